@@ -0,0 +1,362 @@
+// Command jschema generates the json schemas for the types of a go package without writing code, such as:
+//
+//	jschema -pkg ./api -types Node,Data -ref '#/components/schemas' -o schemas.json
+//
+// It generates a temporary program inside the module of the package and runs it with "go run",
+// so it works offline against the local module. The program uses the same version of jschema as the command,
+// it's pinned by a temporary copy of the go.mod of the module, the go.work is ignored for the copy.
+//
+// With "-dialect go" it generates a go file that loads the precomputed schemas without reflection,
+// it's designed for go:generate, such as:
+//
+//	//go:generate go run github.com/ysmood/jschema/cmd/jschema -types Node,Data -dialect go -o schemas_gen.go
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"text/template"
+
+	"github.com/ysmood/jschema/codegen"
+)
+
+func main() {
+	err := run(os.Args[1:], os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+type options struct {
+	pkg     string
+	types   []string
+	ref     string
+	output  string
+	hijacks []string
+	dialect codegen.Dialect
+	nameTag string
+	goPkg   string
+	goFunc  string
+	inline  bool
+}
+
+// dialectGo is the dialect to generate the go source with [codegen.OutputGo].
+const dialectGo codegen.Dialect = "go"
+
+func parseFlags(args []string) (*options, error) {
+	fs := flag.NewFlagSet("jschema", flag.ContinueOnError)
+
+	pkg := fs.String("pkg", ".", "the go package that declares the types")
+	types := fs.String("types", "", "the comma-separated type names to define, such as Node,Data")
+	ref := fs.String("ref", "", `the prefix of each $ref, such as "#/components/schemas"`)
+	output := fs.String("o", "", "the output file, the default is stdout")
+	hijacks := fs.String("hijack", "", "the comma-separated stdlib hijacks to enable: time,bigint,rawmessage")
+	dialect := fs.String("dialect", string(codegen.DialectDefs), "the output dialect: defs, openapi, standalone, go")
+	nameTag := fs.String("name-tag", "json", "the struct tag key to read the property names from")
+	goPkg := fs.String("go-package", "", "the package name of the go dialect, the default is the name of -pkg")
+	goFunc := fs.String("go-func", "RegisterSchemas", "the function name of the go dialect")
+	inline := fs.Bool("inline", false, "inline the definitions that are referenced only once for the standalone dialect")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if *types == "" {
+		return nil, errors.New("jschema: -types is required")
+	}
+
+	opts := &options{
+		pkg:     *pkg,
+		types:   split(*types),
+		ref:     *ref,
+		output:  *output,
+		hijacks: split(*hijacks),
+		dialect: codegen.Dialect(*dialect),
+		nameTag: *nameTag,
+		goPkg:   *goPkg,
+		goFunc:  *goFunc,
+		inline:  *inline,
+	}
+
+	if opts.ref == "" && opts.dialect == codegen.DialectOpenAPI {
+		opts.ref = "#/components/schemas"
+	}
+
+	for _, h := range opts.hijacks {
+		if _, has := hijackMethods[h]; !has {
+			return nil, fmt.Errorf("jschema: unknown hijack %q", h)
+		}
+	}
+
+	return opts, nil
+}
+
+var hijackMethods = map[string]string{
+	"time":       "HijackTime",
+	"bigint":     "HijackBigInt",
+	"rawmessage": "HijackJSONRawMessage",
+}
+
+func run(args []string, stdout io.Writer) error {
+	opts, err := parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	out, err := generate(opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.output == "" {
+		_, err = stdout.Write(out)
+		return err
+	}
+
+	return os.WriteFile(opts.output, out, 0o644) //nolint: gosec
+}
+
+// generate runs a temporary program inside the module of the package to generate the output.
+func generate(opts *options) ([]byte, error) {
+	list, err := goCmd(".", "list", "-f", "{{.ImportPath}}\n{{.Module.Dir}}\n{{.Name}}\n{{.Module.Path}}", opts.pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	info := strings.Split(strings.TrimSpace(string(list)), "\n")
+	importPath, modDir := info[0], info[1]
+
+	if opts.goPkg == "" {
+		opts.goPkg = info[2]
+	}
+
+	dir, err := os.MkdirTemp(modDir, "jschema-tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	src, err := program(importPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "main.go"), src, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	if info[3] == jschemaModule {
+		return goCmd(dir, "run", ".")
+	}
+
+	modFile, err := pin(dir, modDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if modFile == "" {
+		return goCmd(dir, "run", ".")
+	}
+
+	return goCmdEnv(dir, []string{"GOWORK=off"}, "run", "-modfile", modFile, "-mod=mod", ".")
+}
+
+// jschemaModule is the module path of the command.
+const jschemaModule = "github.com/ysmood/jschema"
+
+// pin writes a copy of the go.mod and go.sum of the module into dir that requires the same version of jschema
+// as the command, then the temporary program won't fail or use the old code when the module lacks or pins
+// another version of jschema. It returns the path of the copy, or "" if the version of the command is unknown.
+func pin(dir, modDir string) (string, error) {
+	version, local := self()
+	if version == "" {
+		return "", nil
+	}
+
+	mod := filepath.Join(dir, "jschema.mod")
+
+	b, err := os.ReadFile(filepath.Join(modDir, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(mod, b, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	sums := []string{filepath.Join(modDir, "go.sum")}
+	if local != "" {
+		sums = append(sums, filepath.Join(local, "go.sum"))
+	}
+
+	sum := bytes.NewBuffer(nil)
+	for _, p := range sums {
+		b, err := os.ReadFile(p)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		sum.Write(b)
+	}
+
+	err = os.WriteFile(strings.TrimSuffix(mod, ".mod")+".sum", sum.Bytes(), 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"mod", "edit", "-require", jschemaModule + "@" + version}
+	if local != "" {
+		args = append(args, "-replace", jschemaModule+"="+local)
+	}
+
+	_, err = goCmdEnv(dir, []string{"GOWORK=off"}, append(args, "-modfile", mod)...)
+
+	return mod, err
+}
+
+// self returns the version of the jschema module that builds the command,
+// and the local dir of the module if the command is built from a local copy, such as "go run" inside the repo.
+func self() (string, string) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", ""
+	}
+
+	var m *debug.Module
+	if info.Main.Path == jschemaModule {
+		m = &info.Main
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == jschemaModule {
+			m = dep
+		}
+	}
+
+	if m != nil && m.Replace == nil && strings.HasPrefix(m.Version, "v") {
+		return m.Version, ""
+	}
+
+	// The source path is the local copy of the module, unless it's built with -trimpath.
+	_, file, _, _ := runtime.Caller(0)
+	root := filepath.Dir(filepath.Dir(filepath.Dir(file)))
+
+	b, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil || !strings.HasPrefix(string(b), "module "+jschemaModule+"\n") {
+		return "", ""
+	}
+
+	return "v0.0.0", root
+}
+
+var tplProgram = template.Must(template.New("").Parse(`// Code generated by jschema; DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/ysmood/jschema"
+	"github.com/ysmood/jschema/codegen"
+	target {{printf "%q" .ImportPath}}
+)
+
+func main() {
+	s := jschema.New({{printf "%q" .Ref}}).WithNameTag({{printf "%q" .NameTag}})
+{{- if .Inline}}.WithInlineSingleRefs(){{end}}
+{{range .Hijacks}}
+	s.{{.}}()
+{{- end}}
+
+	types := []reflect.Type{
+{{- range .Types}}
+		reflect.TypeOf((*target.{{.}})(nil)).Elem(),
+{{- end}}
+	}
+
+{{if .Go -}}
+	out, err := codegen.OutputGo(s, {{printf "%q" .GoPkg}}, {{printf "%q" .GoFunc}}, types...)
+{{- else -}}
+	out, err := codegen.Output(s, codegen.Dialect({{printf "%q" .Dialect}}), types...)
+{{- end}}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	_, _ = os.Stdout.Write(out)
+}
+`))
+
+// program returns the source of the temporary program.
+func program(importPath string, opts *options) ([]byte, error) {
+	hijacks := []string{}
+	for _, h := range opts.hijacks {
+		hijacks = append(hijacks, hijackMethods[h])
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	err := tplProgram.Execute(buf, map[string]interface{}{
+		"ImportPath": importPath,
+		"Ref":        opts.ref,
+		"NameTag":    opts.nameTag,
+		"Hijacks":    hijacks,
+		"Dialect":    opts.dialect,
+		"Go":         opts.dialect == dialectGo,
+		"Types":      opts.types,
+		"GoPkg":      opts.goPkg,
+		"GoFunc":     opts.goFunc,
+		"Inline":     opts.inline,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+func goCmd(dir string, args ...string) ([]byte, error) {
+	return goCmdEnv(dir, nil, args...)
+}
+
+// goCmdEnv is like goCmd with the extra environment variables, such as "GOWORK=off" for the -modfile flag.
+func goCmdEnv(dir string, env []string, args ...string) ([]byte, error) {
+	stderr := bytes.NewBuffer(nil)
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stderr = stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("jschema: go %s: %w\n%s", strings.Join(args, " "), err, stderr.String())
+	}
+
+	return out, nil
+}
+
+func split(s string) []string {
+	list := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
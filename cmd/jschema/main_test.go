@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ysmood/got"
+)
+
+func TestRun(t *testing.T) {
+	g := got.T(t)
+
+	out := bytes.NewBuffer(nil)
+	g.E(run([]string{"-pkg", "../../lib/test", "-types", "Enum", "-dialect", "openapi", "-hijack", "time"}, out))
+
+	g.Eq(g.JSON(out.Bytes()), map[string]interface{}{
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"Enum": map[string]interface{}{
+					"description": "github.com/ysmood/jschema/lib/test.Enum",
+					"enum":        []interface{}{"one", "three", "two"},
+					"title":       "Enum",
+				},
+			},
+		},
+	})
+
+	file := filepath.Join(t.TempDir(), "schemas.json")
+	g.E(run([]string{"-pkg", "../../lib/test", "-types", "Enum", "-ref", "#/x", "-dialect", "standalone", "-o", file}, nil))
+
+	b, err := os.ReadFile(file)
+	g.E(err)
+	g.Eq(g.JSON(b).(map[string]interface{})["$ref"], "#/$defs/Enum")
+
+	out.Reset()
+	g.E(run([]string{"-pkg", "../../lib/test", "-types", "Enum", "-dialect", "standalone", "-inline"}, out))
+	g.Eq(g.JSON(out.Bytes()).(map[string]interface{})["title"], "Enum")
+
+	out.Reset()
+	g.E(run([]string{"-pkg", "../../lib/test", "-types", "Enum", "-dialect", "go"}, out))
+	g.Has(out.String(), "package test\n")
+	g.Has(out.String(), "func RegisterSchemas(s jschema.Schemas) {")
+}
+
+func TestRunErrors(t *testing.T) {
+	g := got.T(t)
+
+	g.Eq(run([]string{}, nil).Error(), "jschema: -types is required")
+	g.Eq(run([]string{"-types", "A", "-hijack", "x"}, nil).Error(), `jschema: unknown hijack "x"`)
+	g.Has(run([]string{"-pkg", "../../lib/test", "-types", "Nope"}, nil).Error(), "undefined: target.Nope")
+}
+
+func TestRunOtherModule(t *testing.T) {
+	g := got.T(t)
+
+	// The module doesn't require jschema, the temporary program should use the one of the command.
+	dir := t.TempDir()
+	g.E(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/other\n\ngo 1.20\n"), 0o600))
+	g.E(os.WriteFile(filepath.Join(dir, "item.go"), []byte("package other\n\ntype Item struct {\n\tName string `json:\"name\"`\n}\n"), 0o600))
+
+	wd, err := os.Getwd()
+	g.E(err)
+	g.E(os.Chdir(dir))
+	g.Cleanup(func() { _ = os.Chdir(wd) })
+
+	out := bytes.NewBuffer(nil)
+	g.E(run([]string{"-types", "Item"}, out))
+
+	g.Eq(g.JSON(out.Bytes()).(map[string]interface{})["Item"].(map[string]interface{})["required"], []interface{}{"name"})
+
+	b, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	g.E(err)
+	g.Eq(string(b), "module example.com/other\n\ngo 1.20\n")
+}
@@ -0,0 +1,141 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/ysmood/jschema"
+)
+
+// Literal generates the go source of the package pkg that declares the function fn,
+// the function loads the final contents of s into a [jschema.Schemas] with [jschema.Schemas.Load], such as:
+//
+//	s := jschema.New("")
+//	RegisterSchemas(s)
+//
+// So the schemas are available without the reflection of [jschema.Schemas.DefineT],
+// the json of the loaded schemas is byte-identical to s. The loading [jschema.Schemas] should
+// use the same ref prefix as s.
+func Literal(s jschema.Schemas, pkg, fn string) ([]byte, error) {
+	body := bytes.NewBuffer(nil)
+	types := s.JSON()
+
+	fmt.Fprintf(body, "// %s loads the precomputed schemas into s.\nfunc %s(s jschema.Schemas) {\n", fn, fn)
+
+	for _, r := range s.Refs() {
+		fmt.Fprintf(body, "s.Load(%s, %s)\n", literal(reflect.ValueOf(r)), literal(reflect.ValueOf(types[r.ID])))
+	}
+
+	body.WriteString("}\n")
+
+	buf := bytes.NewBuffer(nil)
+
+	fmt.Fprintf(buf, "// Code generated by jschema; DO NOT EDIT.\n\npackage %s\n\n", pkg)
+
+	if bytes.Contains(body.Bytes(), []byte("json.RawMessage(")) {
+		buf.WriteString("import (\n\"encoding/json\"\n\n\"github.com/ysmood/jschema\"\n)\n\n")
+	} else {
+		buf.WriteString("import \"github.com/ysmood/jschema\"\n\n")
+	}
+
+	buf.Write(body.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+var (
+	tJVal   = reflect.TypeOf((*jschema.JVal)(nil)).Elem()
+	tSchema = reflect.TypeOf(jschema.Schema{})
+)
+
+// literal returns the go expression of the value v, v is a [jschema.Schema] or one of its field values.
+func literal(v reflect.Value) string { //nolint: cyclop
+	t := v.Type()
+
+	if t == tJVal {
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			panic(err)
+		}
+		return fmt.Sprintf("json.RawMessage(%s)", strconv.Quote(string(b)))
+	}
+
+	//nolint: exhaustive
+	switch t.Kind() {
+	case reflect.Ptr:
+		if t.Elem().Kind() == reflect.Struct {
+			return "&" + literal(v.Elem())
+		}
+		return fmt.Sprintf("jschema.Ptr(%s)", literal(v.Elem()))
+
+	case reflect.String:
+		if t.PkgPath() != "" {
+			return fmt.Sprintf("%s(%q)", typeName(t), v.String())
+		}
+		return strconv.Quote(v.String())
+
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+
+	case reflect.Int:
+		return strconv.FormatInt(v.Int(), 10)
+
+	case reflect.Float64:
+		return fmt.Sprintf("float64(%s)", strconv.FormatFloat(v.Float(), 'g', -1, 64))
+
+	case reflect.Slice:
+		b := bytes.NewBufferString(typeName(t) + "{")
+		for i := 0; i < v.Len(); i++ {
+			b.WriteString(literal(v.Index(i)) + ",\n")
+		}
+		b.WriteString("}")
+		return b.String()
+
+	case reflect.Map:
+		keys := []string{}
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+
+		b := bytes.NewBufferString(typeName(t) + "{\n")
+		for _, k := range keys {
+			fmt.Fprintf(b, "%q: %s,\n", k, literal(v.MapIndex(reflect.ValueOf(k))))
+		}
+		b.WriteString("}")
+		return b.String()
+
+	case reflect.Struct:
+		b := bytes.NewBufferString(typeName(t) + "{\n")
+		for i := 0; i < t.NumField(); i++ {
+			f := v.Field(i)
+			if t.Field(i).IsExported() && !f.IsZero() {
+				fmt.Fprintf(b, "%s: %s,\n", t.Field(i).Name, literal(f))
+			}
+		}
+		b.WriteString("}")
+		return b.String()
+	}
+
+	panic(fmt.Sprintf("codegen: unsupported type %s", t))
+}
+
+// typeName returns the go type expression of t in the generated code.
+func typeName(t reflect.Type) string {
+	switch {
+	case t == tSchema:
+		return "jschema.Schema"
+	case t.Kind() == reflect.Ptr:
+		return "*" + typeName(t.Elem())
+	case t.Name() != "" && t.PkgPath() != "":
+		return "jschema." + t.Name()
+	case t.Kind() == reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	}
+	return t.String()
+}
@@ -0,0 +1,59 @@
+package codegen_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+	"github.com/ysmood/jschema/codegen"
+	"github.com/ysmood/jschema/lib/test"
+)
+
+type Other struct {
+	Node *Node `json:"node"`
+}
+
+func TestLiteral(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+	s.Define(Node{})
+	s.Define(Other{})
+	g.E(s.SetDefaults(Other{}))
+	g.E(s.AddExample(test.EnumTwo))
+
+	code, err := codegen.Literal(s, "main", "RegisterSchemas")
+	g.E(err)
+
+	out := run(g, map[string]string{
+		"schemas.go": string(code),
+		"main.go": `package main
+
+import (
+	"fmt"
+
+	"github.com/ysmood/jschema"
+)
+
+func main() {
+	s := jschema.New("")
+	RegisterSchemas(s)
+	fmt.Print(s.String())
+}
+`,
+	})
+
+	g.Eq(out, s.String())
+}
+
+func TestOutputGo(t *testing.T) {
+	g := got.T(t)
+
+	code, err := codegen.OutputGo(jschema.New(""), "api", "Load", reflect.TypeOf(test.EnumOne))
+	g.E(err)
+
+	g.Has(string(code), "package api")
+	g.Has(string(code), "func Load(s jschema.Schemas) {")
+	g.Has(string(code), `s.Load(jschema.Ref{`)
+}
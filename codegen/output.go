@@ -0,0 +1,69 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/ysmood/jschema"
+)
+
+// Dialect is the output format of [Output].
+type Dialect string
+
+const (
+	// DialectDefs outputs the schema list, the same as [jschema.Schemas.String].
+	DialectDefs Dialect = "defs"
+
+	// DialectOpenAPI outputs the schema list as the "components.schemas" of an OpenAPI document.
+	DialectOpenAPI Dialect = "openapi"
+
+	// DialectStandalone outputs a standalone json schema for the types,
+	// if there are more than one types the root schema will be the anyOf them.
+	DialectStandalone Dialect = "standalone"
+)
+
+// Output defines the types in s and encodes the schemas in the dialect.
+func Output(s jschema.Schemas, d Dialect, types ...reflect.Type) ([]byte, error) {
+	roots := defineAll(s, types)
+
+	var v interface{}
+
+	switch d {
+	case DialectDefs:
+		return []byte(s.String()), nil
+
+	case DialectOpenAPI:
+		v = map[string]interface{}{
+			"components": map[string]interface{}{
+				"schemas": s.JSON(),
+			},
+		}
+
+	case DialectStandalone:
+		root := &jschema.Schema{AnyOf: roots}
+		if len(roots) == 1 {
+			root = roots[0]
+		}
+		v = s.ToStandAlone(root)
+
+	default:
+		return nil, fmt.Errorf("codegen: unknown dialect %q", d)
+	}
+
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// OutputGo defines the types in s and generates the go source with [Literal].
+func OutputGo(s jschema.Schemas, pkg, fn string, types ...reflect.Type) ([]byte, error) {
+	defineAll(s, types)
+	return Literal(s, pkg, fn)
+}
+
+func defineAll(s jschema.Schemas, types []reflect.Type) []*jschema.Schema {
+	roots := []*jschema.Schema{}
+	for _, t := range types {
+		roots = append(roots, s.DefineT(t))
+	}
+	return roots
+}
@@ -0,0 +1,38 @@
+package codegen_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+	"github.com/ysmood/jschema/codegen"
+)
+
+func TestOutput(t *testing.T) {
+	g := got.T(t)
+
+	type A struct {
+		B int
+	}
+
+	tA := reflect.TypeOf(A{})
+
+	out, err := codegen.Output(jschema.New(""), codegen.DialectDefs, tA)
+	g.E(err)
+	g.Has(string(out), `"A": {`)
+
+	out, err = codegen.Output(jschema.New("#/components/schemas"), codegen.DialectOpenAPI, tA)
+	g.E(err)
+	g.Eq(g.JSON(out).(map[string]interface{})["components"].(map[string]interface{})["schemas"].(map[string]interface{})["A"].(map[string]interface{})["title"], "A")
+
+	out, err = codegen.Output(jschema.New(""), codegen.DialectStandalone, tA, reflect.TypeOf(1))
+	g.E(err)
+	g.Eq(g.JSON(out).(map[string]interface{})["anyOf"], []interface{}{
+		map[string]interface{}{"$ref": "#/$defs/A"},
+		map[string]interface{}{"type": "integer"},
+	})
+
+	_, err = codegen.Output(jschema.New(""), "x")
+	g.Eq(err.Error(), `codegen: unknown dialect "x"`)
+}
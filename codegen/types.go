@@ -0,0 +1,544 @@
+// Package codegen generates go source code from the schemas.
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ysmood/jschema"
+)
+
+// Types generates the go source of the package pkg that declares a type for each of the defs,
+// such as the defs of a [jschema.Schema] or the result of [jschema.Schemas.JSON].
+// The generated types produce the same schemas when they are defined by [jschema.Schemas.Define]:
+// the objects become structs with the json tags and the [jschema.JTag] tags, the string enums
+// become the types that implement [jschema.EnumString], the other enums become the types that implement
+// [jschema.Enum], and the tuples become the structs with the [jschema.OptionTuple].
+// The optional properties of the struct or fixed array types use the "omitzero" json option of go 1.24,
+// because "omitempty" never omits them.
+func Types(pkg string, defs jschema.Types) ([]byte, error) {
+	g := &typesGen{defs: defs, names: map[string]string{}}
+
+	ids := make([]string, 0, len(defs))
+	for id := range defs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		g.names[id] = jschema.ExportName(id)
+	}
+
+	for _, id := range ids {
+		g.def(g.names[id], defs[id])
+	}
+
+	head := fmt.Sprintf("// Code generated by jschema; DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	if g.hasEnum {
+		head += "import (\n\"encoding/json\"\n\"fmt\"\n)\n"
+	}
+
+	return format.Source(append([]byte(head), g.buf.Bytes()...))
+}
+
+type typesGen struct {
+	buf     bytes.Buffer
+	defs    jschema.Types
+	names   map[string]string
+	hasEnum bool
+}
+
+func (g *typesGen) printf(f string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, f, args...)
+}
+
+func (g *typesGen) def(name string, scm *jschema.Schema) {
+	g.printf("\n")
+
+	if scm.Description != "" {
+		g.printf("%s\n", comment(name+" "+scm.Description))
+	}
+
+	switch {
+	case isStringEnum(scm):
+		g.enum(name, scm)
+
+	case len(scm.Enum) > 0:
+		g.valueEnum(name, scm)
+
+	case len(scm.PrefixItems) > 0:
+		g.printf("type %s struct {\n_ struct{} `jschema:\"tuple\"`\n", name)
+		for i, item := range scm.PrefixItems {
+			g.printf("Item%d %s %s\n", i, g.typeOf(item), structTag(tags("", item)))
+		}
+		g.printf("}\n")
+
+	default:
+		g.printf("type %s %s\n", name, g.typeOf(scm))
+	}
+}
+
+func (g *typesGen) enum(name string, scm *jschema.Schema) {
+	values := []string{}
+	for _, v := range scm.Enum {
+		values = append(values, v.(string)) //nolint: forcetypeassert
+	}
+
+	g.hasEnum = true
+
+	list := constNames(name, values, jschema.ExportName)
+
+	g.printf("type %s string\n\nconst (\n", name)
+	for i, v := range values {
+		g.printf("%s %s = %q\n", list[i], name, v)
+	}
+	g.printf(")\n\n")
+
+	g.printf(`
+var _%[1]sValues = []%[1]s{%[2]s}
+
+// %[1]sValues returns all values of the enum.
+func %[1]sValues() []%[1]s {
+	return _%[1]sValues
+}
+
+// %[1]sString retrieves an enum value from the enum constants string name.
+func %[1]sString(s string) (%[1]s, error) {
+	for _, v := range _%[1]sValues {
+		if string(v) == s {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("%%s does not belong to %[1]s values", s)
+}
+
+// IsA%[1]s returns "true" if the value is listed in the enum definition.
+func (i %[1]s) IsA%[1]s() bool {
+	_, err := %[1]sString(string(i))
+	return err == nil
+}
+
+func (i %[1]s) String() string {
+	return string(i)
+}
+
+// Values implements the jschema.EnumString interface.
+func (%[1]s) Values() []string {
+	list := []string{}
+	for _, v := range _%[1]sValues {
+		list = append(list, string(v))
+	}
+	return list
+}
+
+// MarshalJSON implements the json.Marshaler interface for %[1]s.
+func (i %[1]s) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(i))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for %[1]s.
+func (i *%[1]s) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%[1]s should be a string, got %%s", data)
+	}
+
+	var err error
+	*i, err = %[1]sString(s)
+	return err
+}
+`, name, strings.Join(list, ", "))
+}
+
+// valueEnum generates the enum of the numbers, booleans, or the mixed json values.
+// The enum of numbers or booleans has the constants, the rest use the raw json as the underlying type.
+func (g *typesGen) valueEnum(name string, scm *jschema.Schema) {
+	values := []interface{}{}
+	for _, v := range scm.Enum {
+		b, _ := json.Marshal(v)
+		var x interface{}
+		_ = json.Unmarshal(b, &x)
+		values = append(values, x)
+	}
+
+	g.hasEnum = true
+
+	base := enumBase(values)
+
+	list := []string{}
+	if base == "json.RawMessage" {
+		for _, v := range values {
+			b, _ := json.Marshal(v)
+			list = append(list, fmt.Sprintf("%s(%q)", name, b))
+		}
+
+		g.printf("type %s json.RawMessage\n", name)
+	} else {
+		texts := []string{}
+		for _, v := range values {
+			b, _ := json.Marshal(v)
+			texts = append(texts, string(b))
+		}
+
+		list = constNames(name, texts, func(text string) string {
+			if base == "bool" {
+				return jschema.ExportName(text)
+			}
+			// Such as "-1.5" to "Neg1_5".
+			return strings.NewReplacer("-", "Neg", ".", "_", "+", "").Replace(text)
+		})
+
+		g.printf("type %s %s\n\nconst (\n", name, base)
+		for i, text := range texts {
+			g.printf("%s %s = %s\n", list[i], name, text)
+		}
+		g.printf(")\n")
+	}
+
+	g.printf(`
+var _%[1]sValues = []%[1]s{%[2]s}
+
+// %[1]sValues returns all values of the enum.
+func %[1]sValues() []%[1]s {
+	return _%[1]sValues
+}
+
+// Values implements the jschema.Enum interface.
+func (%[1]s) Values() []json.RawMessage {
+	list := []json.RawMessage{}
+	for _, v := range _%[1]sValues {
+		b, _ := v.MarshalJSON()
+		list = append(list, b)
+	}
+	return list
+}
+
+// MarshalJSON implements the json.Marshaler interface for %[1]s.
+func (i %[1]s) MarshalJSON() ([]byte, error) {
+	return json.Marshal(%[3]s(i))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for %[1]s.
+func (i *%[1]s) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	b, _ := json.Marshal(v)
+	for _, x := range _%[1]sValues {
+		if raw, _ := x.MarshalJSON(); string(raw) == string(b) {
+			*i = x
+			return nil
+		}
+	}
+	return fmt.Errorf("%%s does not belong to %[1]s values", data)
+}
+`, name, strings.Join(list, ", "), base)
+}
+
+// enumBase returns the go type of the decoded json values, it's "json.RawMessage" if they are not the same kind.
+func enumBase(values []interface{}) string {
+	base := ""
+	for _, v := range values {
+		t := "json.RawMessage"
+		switch v := v.(type) {
+		case bool:
+			t = "bool"
+		case float64:
+			t = "float64"
+			if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+				t = "int"
+			}
+		}
+
+		switch {
+		case base == "" || base == t:
+			base = t
+		case base == "int" && t == "float64", base == "float64" && t == "int":
+			base = "float64"
+		default:
+			return "json.RawMessage"
+		}
+	}
+	return base
+}
+
+// constNames returns the constant names of the enum values, the collided ones get a numeric suffix.
+func constNames(name string, values []string, label func(string) string) []string {
+	used := map[string]bool{}
+	list := []string{}
+
+	for _, v := range values {
+		c := name + label(v)
+		for i := 1; used[c]; i++ {
+			c = fmt.Sprintf("%s%s%d", name, label(v), i)
+		}
+		used[c] = true
+
+		list = append(list, c)
+	}
+
+	return list
+}
+
+// typeOf returns the go type expression of the schema.
+func (g *typesGen) typeOf(scm *jschema.Schema) string { //nolint: cyclop
+	if scm == nil {
+		return "interface{}"
+	}
+
+	if scm.Ref != nil {
+		if name, has := g.names[scm.Ref.ID]; has {
+			return name
+		}
+		return "interface{}"
+	}
+
+	if len(scm.AnyOf) == 2 && scm.AnyOf[1].Type == jschema.TypeNull {
+		return "*" + g.typeOf(scm.AnyOf[0])
+	}
+
+	if len(scm.AnyOf) > 0 {
+		return "interface{}"
+	}
+
+	switch scm.Type {
+	case jschema.TypeString:
+		return "string"
+	case jschema.TypeInteger:
+		return "int"
+	case jschema.TypeNumber:
+		return "float64"
+	case jschema.TypeBool:
+		return "bool"
+	case jschema.TypeArray:
+		if scm.Items != nil && scm.MinItems != nil && scm.MaxItems != nil && *scm.MinItems == *scm.MaxItems {
+			return fmt.Sprintf("[%d]%s", *scm.MinItems, g.typeOf(scm.Items))
+		}
+		return "[]" + g.typeOf(scm.Items)
+	case jschema.TypeObject:
+		return g.objectType(scm)
+	}
+
+	return "interface{}"
+}
+
+func (g *typesGen) objectType(scm *jschema.Schema) string {
+	if v, _ := mapValue(scm); v != nil {
+		k := "string"
+		if scm.PropertyNames != nil {
+			switch scm.PropertyNames.Pattern {
+			case "^-?[0-9]+$":
+				k = "int"
+			case "^[0-9]+$":
+				k = "uint"
+			}
+		}
+		return fmt.Sprintf("map[%s]%s", k, g.typeOf(v))
+	}
+
+	if len(scm.Properties) == 0 && (scm.AdditionalProperties == nil || *scm.AdditionalProperties) {
+		return "map[string]interface{}"
+	}
+
+	b := &strings.Builder{}
+	b.WriteString("struct {\n")
+
+	used := map[string]bool{}
+
+	for _, p := range propertyOrder(scm) {
+		field := jschema.ExportName(p)
+		for i := 1; used[field]; i++ {
+			field = fmt.Sprintf("%s%d", jschema.ExportName(p), i)
+		}
+		used[field] = true
+
+		prop := scm.Properties[p]
+
+		json := p
+		if !scm.Required.Has(p) {
+			if g.isStruct(prop) {
+				json += ",omitzero"
+			} else {
+				json += ",omitempty"
+			}
+		}
+
+		t := append([]string{fmt.Sprintf("json:%q", json)}, tags("", prop)...)
+		fmt.Fprintf(b, "%s %s %s\n", field, g.typeOf(prop), structTag(t))
+	}
+
+	b.WriteString("}")
+
+	return b.String()
+}
+
+var regFixedArray = regexp.MustCompile(`^\[\d+\]`)
+
+// isStruct reports whether the go type of the schema is a struct or a fixed array, "omitempty" never omits them.
+func (g *typesGen) isStruct(scm *jschema.Schema) bool {
+	if scm.Ref != nil {
+		def, has := g.defs[scm.Ref.ID]
+		if !has || len(def.Enum) > 0 {
+			return false
+		}
+		if len(def.PrefixItems) > 0 {
+			return true
+		}
+		scm = def
+	}
+
+	t := g.typeOf(scm)
+
+	return strings.HasPrefix(t, "struct") || regFixedArray.MatchString(t)
+}
+
+// propertyOrder returns the required properties in order, then the optional ones sorted.
+func propertyOrder(scm *jschema.Schema) []string {
+	list := []string{}
+	for _, p := range scm.Required {
+		if _, has := scm.Properties[p]; has {
+			list = append(list, p)
+		}
+	}
+
+	optional := []string{}
+	for p := range scm.Properties {
+		if !scm.Required.Has(p) {
+			optional = append(optional, p)
+		}
+	}
+	sort.Strings(optional)
+
+	return append(list, optional...)
+}
+
+// mapValue returns the schema of the map values and the pattern of the keys.
+func mapValue(scm *jschema.Schema) (*jschema.Schema, string) {
+	if scm.AdditionalPropertiesSchema != nil {
+		return scm.AdditionalPropertiesSchema, ""
+	}
+
+	if len(scm.Properties) == 0 && len(scm.PatternProperties) == 1 {
+		for k, v := range scm.PatternProperties {
+			return v, k
+		}
+	}
+
+	return nil, ""
+}
+
+// tags returns the [jschema.JTag] tags of the schema and its nested items, keys, and values.
+func tags(prefix string, scm *jschema.Schema) []string {
+	if scm == nil {
+		return nil
+	}
+
+	list := []string{}
+
+	add := func(name jschema.JTag, v string) {
+		if v != "" {
+			list = append(list, fmt.Sprintf("%s%s:%q", prefix, name, v))
+		}
+	}
+
+	num := func(name jschema.JTag, v *float64) {
+		if v != nil {
+			add(name, strconv.FormatFloat(*v, 'f', -1, 64))
+		}
+	}
+
+	add(jschema.JTagDescription, scm.Description)
+	add(jschema.JTagFormat, scm.Format)
+	add(jschema.JTagPattern, scm.Pattern)
+	num(jschema.JTagMin, scm.Min)
+	num(jschema.JTagMax, scm.Max)
+	num(jschema.JTagMinLen, scm.MinLen)
+	num(jschema.JTagMaxLen, scm.MaxLen)
+
+	if scm.Default != nil {
+		b, _ := json.Marshal(scm.Default)
+		add(jschema.JTagDefault, string(b))
+	}
+
+	if len(scm.Examples) > 0 {
+		b, _ := json.Marshal(scm.Examples)
+		add(jschema.JTagExamples, string(b))
+	}
+
+	// the collection constraints of a pointer are on its non-null schema
+	inner := scm
+	if len(scm.AnyOf) == 2 && scm.AnyOf[1].Type == jschema.TypeNull && scm.AnyOf[0].Ref == nil {
+		inner = scm.AnyOf[0]
+	}
+
+	fixed := inner.Items != nil && inner.MinItems != nil && inner.MaxItems != nil && *inner.MinItems == *inner.MaxItems
+
+	if inner.Type == jschema.TypeArray && !fixed {
+		if inner.MinItems != nil {
+			add(jschema.JTagMinItems, strconv.Itoa(*inner.MinItems))
+		}
+		if inner.MaxItems != nil {
+			add(jschema.JTagMaxItems, strconv.Itoa(*inner.MaxItems))
+		}
+	}
+
+	if inner.Type == jschema.TypeArray {
+		list = append(list, tags(prefix+jschema.JTagItemPrefix, inner.Items)...)
+	}
+
+	if v, key := mapValue(inner); v != nil {
+		list = append(list, tags(prefix+jschema.JTagValuePrefix, v)...)
+		if key != "" {
+			list = append(list, fmt.Sprintf("%s%s%s:%q", prefix, jschema.JTagKeyPrefix, jschema.JTagPattern, key))
+		}
+		if inner.PropertyNames != nil {
+			keys := *inner.PropertyNames
+			if keys.Pattern == "^-?[0-9]+$" || keys.Pattern == "^[0-9]+$" {
+				keys.Pattern = ""
+			}
+			list = append(list, tags(prefix+jschema.JTagKeyPrefix, &keys)...)
+		}
+	}
+
+	return list
+}
+
+func structTag(list []string) string {
+	if len(list) == 0 {
+		return ""
+	}
+
+	t := strings.Join(list, " ")
+	if strings.Contains(t, "`") {
+		return strconv.Quote(t)
+	}
+
+	return "`" + t + "`"
+}
+
+func comment(s string) string {
+	return "// " + strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n// ")
+}
+
+func isStringEnum(scm *jschema.Schema) bool {
+	if len(scm.Enum) == 0 {
+		return false
+	}
+
+	for _, v := range scm.Enum {
+		if _, ok := v.(string); !ok {
+			return false
+		}
+	}
+
+	return true
+}
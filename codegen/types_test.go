@@ -0,0 +1,137 @@
+package codegen_test
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+	"github.com/ysmood/jschema/codegen"
+	"github.com/ysmood/jschema/lib/test"
+)
+
+type Node struct {
+	ID       int                `json:"id" min:"1" default:"1" description:"the id"`
+	Name     string             `json:"name,omitempty" pattern:"^[a-z]+$" minLen:"1" examples:"[\"a\",\"b\"]"`
+	Children []*Node            `json:"children" maxItems:"10" item-description:"child"`
+	Enum     test.Enum          `json:"enum"`
+	Matrix   [][2]float64       `json:"matrix" item-item-max:"1"`
+	Labels   map[string]string  `json:"labels,omitempty" key-pattern:"^[a-z]" value-maxLen:"3"`
+	Index    map[int]*Point     `json:"index,omitempty"`
+	Point    *Point             `json:"point"`
+	Extra    interface{}        `json:"extra,omitempty"`
+	Inline   struct{ A bool }   `json:"inline"`
+	Tags     *[]string          `json:"tags" minItems:"1"`
+	Any      map[string]float64 `json:"any" value-min:"0"`
+	Opt      struct{ B bool }   `json:"opt,omitzero"`
+	Pt       Point              `json:"pt,omitzero"`
+	Pair     [2]int             `json:"pair,omitzero"`
+	Level    Level              `json:"level"`
+	Mixed    Mixed              `json:"mixed,omitempty"`
+	Sep      Sep                `json:"sep"`
+}
+
+type Level int
+
+func (Level) Values() []json.RawMessage {
+	return []json.RawMessage{json.RawMessage(`1`), json.RawMessage(`2`), json.RawMessage(`-1`)}
+}
+
+func (l Level) MarshalJSON() ([]byte, error) { return json.Marshal(int(l)) }
+
+func (l *Level) UnmarshalJSON(b []byte) error { return json.Unmarshal(b, (*int)(l)) }
+
+type Mixed json.RawMessage
+
+func (Mixed) Values() []json.RawMessage {
+	return []json.RawMessage{json.RawMessage(`"a"`), json.RawMessage(`1.5`), json.RawMessage(`null`)}
+}
+
+func (m Mixed) MarshalJSON() ([]byte, error) { return json.RawMessage(m).MarshalJSON() }
+
+func (m *Mixed) UnmarshalJSON(b []byte) error { return (*json.RawMessage)(m).UnmarshalJSON(b) }
+
+type Sep string
+
+func (Sep) Values() []string { return []string{"a-b", "a_b"} }
+
+func (e Sep) MarshalJSON() ([]byte, error) { return json.Marshal(string(e)) }
+
+func (e *Sep) UnmarshalJSON(b []byte) error { return json.Unmarshal(b, (*string)(e)) }
+
+type Point struct {
+	_ struct{} `jschema:"tuple"`
+	X float64  `min:"0"`
+	Y float64
+}
+
+func TestTypes(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+	s.Define(Node{})
+
+	var defs jschema.Types
+	g.E(json.Unmarshal([]byte(s.String()), &defs))
+
+	code, err := codegen.Types("main", defs)
+	g.E(err)
+
+	g.Snapshot("types", string(code))
+
+	out := run(g, map[string]string{
+		"types.go": string(code),
+		"main.go": `package main
+
+import (
+	"fmt"
+
+	"github.com/ysmood/jschema"
+)
+
+func main() {
+	s := jschema.New("")
+	s.Define(Node{})
+	fmt.Print(s.String())
+}
+`,
+	})
+
+	g.Eq(normalize(g, out), normalize(g, s.String()))
+}
+
+// run the go files as a main package and returns the stdout.
+func run(g got.G, files map[string]string) string {
+	dir, err := os.MkdirTemp(".", "tmp")
+	g.E(err)
+	g.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	for name, content := range files {
+		g.E(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	g.E(err)
+
+	return string(out)
+}
+
+// normalize removes the titles and descriptions of the definitions, they depend on the go package.
+func normalize(g got.G, s string) jschema.Types {
+	var defs jschema.Types
+	g.E(json.Unmarshal([]byte(s), &defs))
+
+	for _, d := range defs {
+		d.Title = ""
+		d.Description = ""
+	}
+
+	return defs
+}
@@ -0,0 +1,523 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ysmood/jschema"
+)
+
+var (
+	tJSONMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	tTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	tString        = reflect.TypeOf("")
+)
+
+// Validators generates the go source of the package pkg that declares a Validate method for each of the struct types:
+//
+//	func (v Node) Validate() error
+//
+// The types are defined in s, the methods check the constraints of the fields without the reflection, such as
+// the "min", "max", "minLen", "maxLen", "pattern", "minItems", "maxItems" tags and the enums.
+// The returned errors are the same [jschema.ValidationErrors] as the ones of [jschema.Schemas.Validate],
+// except that the formats and the types with custom encoders other than the enums are not checked.
+// The fields of the types are validated by the methods of the types, so the struct types that the fields use
+// should be in types too, otherwise they are skipped. The types must be in the same package.
+func Validators(s jschema.Schemas, pkg string, types ...reflect.Type) ([]byte, error) {
+	g := &validatorsGen{s: s, types: map[reflect.Type]bool{}, imports: map[string]bool{}}
+
+	for _, t := range types {
+		if t.Kind() != reflect.Struct || t.Name() == "" {
+			return nil, fmt.Errorf("codegen: %s is not a named struct", t)
+		}
+		if t.PkgPath() != types[0].PkgPath() {
+			return nil, fmt.Errorf("codegen: %s is not in the package of %s", t, types[0])
+		}
+		g.types[t] = true
+	}
+
+	for _, t := range types {
+		s.DefineT(t)
+		g.method(t)
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	fmt.Fprintf(buf, "// Code generated by jschema; DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	for _, p := range []string{"reflect", "regexp", "strconv", "unicode/utf8"} {
+		if g.imports[p] {
+			fmt.Fprintf(buf, "%q\n", p)
+		}
+	}
+	buf.WriteString("\n\"github.com/ysmood/jschema\"\n)\n")
+
+	if len(g.patterns) > 0 {
+		buf.WriteString("\nvar (\n")
+		for i, p := range g.patterns {
+			fmt.Fprintf(buf, "jschemaPattern%d = regexp.MustCompile(%s)\n", i, strconv.Quote(p))
+		}
+		buf.WriteString(")\n")
+	}
+
+	buf.Write(g.buf.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+type validatorsGen struct {
+	s        jschema.Schemas
+	buf      bytes.Buffer
+	types    map[reflect.Type]bool
+	imports  map[string]bool
+	patterns []string
+	depth    int
+
+	// omitEmpty is true when the value of the next [validatorsGen.value] is an omitempty field.
+	omitEmpty bool
+}
+
+func (g *validatorsGen) printf(f string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, f, args...)
+}
+
+// sub returns the code that fn generates.
+func (g *validatorsGen) sub(fn func()) string {
+	buf := g.buf
+	g.buf = bytes.Buffer{}
+	fn()
+	code := g.buf.String()
+	g.buf = buf
+	return code
+}
+
+func (g *validatorsGen) fail(path, keyword string, args ...string) {
+	g.printf("errs = append(errs, jschema.NewValidationError(%s, %q", path, keyword)
+	for _, a := range args {
+		g.printf(", %s", a)
+	}
+	g.printf("))\n")
+}
+
+func (g *validatorsGen) method(t reflect.Type) {
+	g.printf(`
+// Validate validates v against the schema of %[1]s, the errors are the same as the ones of [jschema.Schemas.Validate].
+func (v %[1]s) Validate() error {
+	if errs := v.jschemaValidate("", nil); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (v %[1]s) jschemaValidate(path string, errs jschema.ValidationErrors) jschema.ValidationErrors {
+`, t.Name())
+
+	g.fields("v", t, "path")
+
+	g.printf("return errs\n}\n")
+}
+
+// fields generates the checks of the fields of the struct value e.
+func (g *validatorsGen) fields(e string, t reflect.Type, path string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		scm := g.s.DefineFieldT(f)
+		if scm == nil {
+			continue
+		}
+
+		fe := e + "." + f.Name
+
+		if g.embedded(f) {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				// The fields of the nil embedded pointer are omitted.
+				missing := g.sub(func() {
+					for _, name := range scm.Required {
+						g.fail(path, "required", strconv.Quote(name))
+					}
+				})
+				code := g.sub(func() { g.fields(fe, ft.Elem(), path) })
+				switch {
+				case missing != "":
+					g.printf("if %s == nil {\n%s} else {\n%s}\n", fe, missing, code)
+				case code != "":
+					g.printf("if %s != nil {\n%s}\n", fe, code)
+				}
+			} else if ft.Kind() == reflect.Struct {
+				g.fields(fe, ft, path)
+			}
+			continue
+		}
+
+		for name, p := range scm.Properties {
+			if p.Type == jschema.TypeString && isNumber(indirect(f.Type).Kind()) {
+				// The number encoded as string by the json ",string" option.
+				continue
+			}
+			cond := g.present(fe, f)
+			code := g.sub(func() {
+				g.omitEmpty = cond != ""
+				g.value(fe, f.Type, p, fmt.Sprintf("%s+%s", path, strconv.Quote("/"+name)))
+			})
+			if cond != "" && code != "" {
+				if strings.HasPrefix(cond, "!reflect.") {
+					g.imports["reflect"] = true
+				}
+				code = fmt.Sprintf("if %s {\n%s}\n", cond, code)
+			}
+			g.buf.WriteString(code)
+		}
+	}
+}
+
+// value generates the checks of the value e of type t, path is the go expression of the json pointer of e.
+func (g *validatorsGen) value(e string, t reflect.Type, scm *jschema.Schema, path string) { //nolint: cyclop
+	scm = g.resolve(scm)
+
+	// The empty slices and maps of the omitempty fields are omitted, so they are never null.
+	omit := g.omitEmpty
+	g.omitEmpty = false
+
+	if t.Kind() == reflect.Ptr {
+		g.pointer(e, t, scm, path)
+		return
+	}
+
+	if g.types[t] {
+		g.printf("errs = %s.jschemaValidate(%s, errs)\n", e, path)
+		return
+	}
+
+	if len(scm.Enum) > 0 {
+		g.enum(e, t, scm, path)
+		return
+	}
+
+	if implements(t, tJSONMarshaler) || implements(t, tTextMarshaler) {
+		return
+	}
+
+	//nolint: exhaustive
+	switch k := t.Kind(); {
+	case isNumber(k) || k == reflect.String:
+		g.scalar(e, t, scm, path)
+
+	case k == reflect.Slice || k == reflect.Array:
+		g.list(e, t, scm, path, omit)
+
+	case k == reflect.Map:
+		g.dict(e, t, scm, path, omit)
+
+	case k == reflect.Struct && t.Name() == "":
+		g.fields(e, t, path)
+	}
+}
+
+// scalar generates the checks of the number or string value e.
+func (g *validatorsGen) scalar(e string, t reflect.Type, scm *jschema.Schema, path string) {
+	switch k := t.Kind(); {
+	case isNumber(k):
+		if scm.Min != nil {
+			g.printf("if float64(%s) < %s {\n", e, num(*scm.Min))
+			g.fail(path, "minimum", num(*scm.Min))
+			g.printf("}\n")
+		}
+		if scm.Max != nil {
+			g.printf("if float64(%s) > %s {\n", e, num(*scm.Max))
+			g.fail(path, "maximum", num(*scm.Max))
+			g.printf("}\n")
+		}
+
+	case k == reflect.String:
+		if t != tString {
+			e = fmt.Sprintf("string(%s)", e)
+		}
+		g.str(e, scm, path)
+	}
+}
+
+// resolve returns the definition of the ref, the keywords beside the $ref are ignored, the same as the validator.
+func (g *validatorsGen) resolve(scm *jschema.Schema) *jschema.Schema {
+	if scm.Ref != nil {
+		if def := g.s.Get(scm.Ref.ID); def != nil {
+			return def
+		}
+	}
+	return scm
+}
+
+func (g *validatorsGen) pointer(e string, t reflect.Type, scm *jschema.Schema, path string) {
+	inner, nullable := scm, false
+	if len(scm.AnyOf) == 2 && scm.AnyOf[1].Type == jschema.TypeNull {
+		inner, nullable = scm.AnyOf[0], true
+	}
+
+	v := "(*" + e + ")"
+	code := g.sub(func() { g.value(v, t.Elem(), inner, path) })
+
+	if !nullable {
+		if expected := g.resolve(inner).Type; expected != "" {
+			g.printf("if %s == nil {\n", e)
+			g.fail(path, "type", strconv.Quote(string(expected)), `"null"`)
+			g.printf("} else {\n%s}\n", code)
+		} else if code != "" {
+			g.printf("if %s != nil {\n%s}\n", e, code)
+		}
+		return
+	}
+
+	// The value must match the non-null branch of the anyOf, and the keywords beside the anyOf.
+	if code != "" {
+		n := fmt.Sprintf("n%d", g.depth)
+		code = g.sub(func() {
+			g.printf("%s := len(errs)\n%sif len(errs) > %s {\n", n, code, n)
+			g.fail(path, "anyOf")
+			g.printf("}\n")
+		})
+	}
+
+	code += g.sub(func() { g.scalar(v, t.Elem(), scm, path) })
+
+	if code != "" {
+		g.printf("if %s != nil {\n%s}\n", e, code)
+	}
+}
+
+func (g *validatorsGen) enum(e string, t reflect.Type, scm *jschema.Schema, path string) {
+	list := []string{}
+	cases := []string{}
+	for _, v := range scm.Enum {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+		list = append(list, string(b))
+		cases = append(cases, strconv.Quote(string(b)))
+	}
+	allowed := strconv.Quote(strings.Join(list, ", "))
+
+	switch {
+	case implements(t, tJSONMarshaler):
+		g.printf("switch b, _ := %s.MarshalJSON(); string(b) {\ncase %s:\ndefault:\n", e, strings.Join(cases, ", "))
+
+	case t.Kind() == reflect.String:
+		cases = []string{}
+		for _, v := range scm.Enum {
+			s, ok := v.(string)
+			if !ok {
+				return
+			}
+			cases = append(cases, strconv.Quote(s))
+		}
+		g.printf("switch string(%s) {\ncase %s:\ndefault:\n", e, strings.Join(cases, ", "))
+
+	default:
+		return
+	}
+
+	g.fail(path, "enum", allowed)
+	g.printf("}\n")
+}
+
+func (g *validatorsGen) str(e string, scm *jschema.Schema, path string) {
+	if scm.MinLen != nil {
+		g.imports["unicode/utf8"] = true
+		g.printf("if utf8.RuneCountInString(%s) < %s {\n", e, num(*scm.MinLen))
+		g.fail(path, "minLength", num(*scm.MinLen))
+		g.printf("}\n")
+	}
+	if scm.MaxLen != nil {
+		g.imports["unicode/utf8"] = true
+		g.printf("if utf8.RuneCountInString(%s) > %s {\n", e, num(*scm.MaxLen))
+		g.fail(path, "maxLength", num(*scm.MaxLen))
+		g.printf("}\n")
+	}
+	if scm.Pattern != "" {
+		g.printf("if !%s.MatchString(%s) {\n", g.pattern(scm.Pattern), e)
+		g.fail(path, "pattern", strconv.Quote(scm.Pattern))
+		g.printf("}\n")
+	}
+}
+
+// pattern returns the name of the compiled regexp variable of the pattern.
+func (g *validatorsGen) pattern(p string) string {
+	g.imports["regexp"] = true
+
+	for i, v := range g.patterns {
+		if v == p {
+			return fmt.Sprintf("jschemaPattern%d", i)
+		}
+	}
+	g.patterns = append(g.patterns, p)
+	return fmt.Sprintf("jschemaPattern%d", len(g.patterns)-1)
+}
+
+func (g *validatorsGen) list(e string, t reflect.Type, scm *jschema.Schema, path string, omit bool) {
+	if t.Elem().Kind() == reflect.Uint8 || scm.Type != jschema.TypeArray {
+		// The []byte is encoded as base64 string.
+		return
+	}
+
+	if t.Kind() == reflect.Slice {
+		if !omit {
+			g.printf("if %s == nil {\n", e)
+			g.fail(path, "type", `"array"`, `"null"`)
+			g.printf("} else {\n")
+			defer g.printf("}\n")
+		}
+
+		if scm.MinItems != nil {
+			g.printf("if len(%s) < %d {\n", e, *scm.MinItems)
+			g.fail(path, "minItems", strconv.Itoa(*scm.MinItems))
+			g.printf("}\n")
+		}
+		if scm.MaxItems != nil {
+			g.printf("if len(%s) > %d {\n", e, *scm.MaxItems)
+			g.fail(path, "maxItems", strconv.Itoa(*scm.MaxItems))
+			g.printf("}\n")
+		}
+	}
+
+	if scm.Items == nil {
+		return
+	}
+
+	g.depth++
+	i, x := fmt.Sprintf("i%d", g.depth), fmt.Sprintf("x%d", g.depth)
+	code := g.sub(func() { g.value(x, t.Elem(), scm.Items, fmt.Sprintf(`%s+"/"+strconv.Itoa(%s)`, path, i)) })
+	g.depth--
+
+	if code != "" {
+		g.imports["strconv"] = true
+		g.printf("for %s, %s := range %s {\n%s}\n", i, x, e, code)
+	}
+}
+
+func (g *validatorsGen) dict(e string, t reflect.Type, scm *jschema.Schema, path string, omit bool) {
+	if scm.Type != jschema.TypeObject {
+		return
+	}
+
+	if !omit {
+		g.printf("if %s == nil {\n", e)
+		g.fail(path, "type", `"object"`, `"null"`)
+		g.printf("} else {\n")
+		defer g.printf("}\n")
+	}
+
+	values, pattern := mapValue(scm)
+
+	var key string
+	switch kt := t.Key(); {
+	case implements(kt, tTextMarshaler):
+		return
+	case kt.Kind() == reflect.String:
+		key = "string(%s)"
+	case kt.Kind() >= reflect.Int && kt.Kind() <= reflect.Int64:
+		g.imports["strconv"] = true
+		key = "strconv.FormatInt(int64(%s), 10)"
+	case kt.Kind() >= reflect.Uint && kt.Kind() <= reflect.Uintptr:
+		g.imports["strconv"] = true
+		key = "strconv.FormatUint(uint64(%s), 10)"
+	default:
+		return
+	}
+
+	g.depth++
+	k, x := fmt.Sprintf("k%d", g.depth), fmt.Sprintf("x%d", g.depth)
+	key = fmt.Sprintf(key, k)
+
+	code := g.sub(func() {
+		if pattern != "" && scm.AdditionalProperties != nil && !*scm.AdditionalProperties {
+			g.printf("if !%s.MatchString(%s) {\n", g.pattern(pattern), key)
+			g.fail(path, "additionalProperties", key)
+			g.printf("}\n")
+		}
+
+		if names := scm.PropertyNames; names != nil {
+			if code := g.sub(func() { g.str(key, names, path) }); code != "" {
+				n := fmt.Sprintf("n%d", g.depth)
+				g.printf("%s := len(errs)\n%sif len(errs) > %s {\n", n, code, n)
+				g.fail(path, "propertyNames", key)
+				g.printf("}\n")
+			}
+		}
+
+		if values != nil {
+			g.value(x, t.Elem(), values, fmt.Sprintf(`%s+"/"+%s`, path, key))
+		}
+	})
+	g.depth--
+
+	if code != "" {
+		if strings.Contains(code, x) {
+			k += ", " + x
+		}
+		g.printf("for %s := range %s {\n%s}\n", k, e, code)
+	}
+}
+
+// embedded reports whether the encoder flattens the fields of the embedded struct field f into its parent.
+func (g *validatorsGen) embedded(f reflect.StructField) bool {
+	return g.s.FieldInline(f) && indirect(f.Type).Kind() == reflect.Struct
+}
+
+var tIsZero = reflect.TypeOf((*interface{ IsZero() bool })(nil)).Elem()
+
+// present returns the condition that the field value e is not omitted by the encoder, such as the omitempty json option.
+// The zero structs and arrays are checked by the reflect package.
+func (g *validatorsGen) present(e string, f reflect.StructField) string {
+	omit := g.s.FieldOmit(f)
+	if omit == jschema.OmitNever {
+		return ""
+	}
+
+	t := f.Type
+	if omit == jschema.OmitZero && t.Implements(tIsZero) {
+		return "!" + e + ".IsZero()"
+	}
+
+	//nolint: exhaustive
+	switch k := t.Kind(); {
+	case k == reflect.Ptr || k == reflect.Interface:
+		return e + " != nil"
+	case (k == reflect.Slice || k == reflect.Map) && omit == jschema.OmitZero:
+		return e + " != nil"
+	case k == reflect.Slice || k == reflect.Map || k == reflect.String:
+		return "len(" + e + ") != 0"
+	case k == reflect.Array && omit == jschema.OmitEmpty:
+		return "len(" + e + ") != 0"
+	case k == reflect.Array || k == reflect.Struct:
+		return "!reflect.ValueOf(" + e + ").IsZero()"
+	case isNumber(k):
+		return e + " != 0"
+	}
+	return ""
+}
+
+func implements(t, i reflect.Type) bool {
+	return t.Implements(i) || reflect.PtrTo(t).Implements(i)
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func isNumber(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+// num returns the go literal of the number f.
+func num(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,229 @@
+package codegen_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+	"github.com/ysmood/jschema/codegen"
+	"github.com/ysmood/jschema/lib/test"
+)
+
+// The types are the same as the ones in the itemTypes.
+type Base struct {
+	ID string `json:"id" maxLen:"3"`
+}
+
+type Meta struct {
+	Note string `json:"note" maxLen:"2"`
+}
+
+type Item struct {
+	Base
+	Name  string    `json:"name" minLen:"1" maxLen:"5" pattern:"^[a-z]+$"`
+	Count int       `json:"count" min:"1" max:"10"`
+	Price *float64  `json:"price" min:"0"`
+	Enum  test.Enum `json:"enum"`
+}
+
+type Order struct {
+	Items  []Item            `json:"items" minItems:"1" maxItems:"3"`
+	Tags   []string          `json:"tags,omitempty" item-maxLen:"2"`
+	Labels map[string]int    `json:"labels" key-pattern:"^[a-z]+$" value-max:"9"`
+	Names  map[string]string `json:"names,omitempty" key-maxLen:"2"`
+	Parent *Order            `json:"parent"`
+	Point  [2]int            `json:"point" item-min:"0"`
+	Inline struct {
+		A int `json:"a" max:"1"`
+	} `json:"inline"`
+	Matrix [][]int `json:"matrix,omitempty" item-item-max:"1"`
+	*Meta
+}
+
+const itemTypes = `package main
+
+import "github.com/ysmood/jschema/lib/test"
+
+type Base struct {
+	ID string ` + "`json:\"id\" maxLen:\"3\"`" + `
+}
+
+type Meta struct {
+	Note string ` + "`json:\"note\" maxLen:\"2\"`" + `
+}
+
+type Item struct {
+	Base
+	Name  string    ` + "`json:\"name\" minLen:\"1\" maxLen:\"5\" pattern:\"^[a-z]+$\"`" + `
+	Count int       ` + "`json:\"count\" min:\"1\" max:\"10\"`" + `
+	Price *float64  ` + "`json:\"price\" min:\"0\"`" + `
+	Enum  test.Enum ` + "`json:\"enum\"`" + `
+}
+
+type Order struct {
+	Items  []Item            ` + "`json:\"items\" minItems:\"1\" maxItems:\"3\"`" + `
+	Tags   []string          ` + "`json:\"tags,omitempty\" item-maxLen:\"2\"`" + `
+	Labels map[string]int    ` + "`json:\"labels\" key-pattern:\"^[a-z]+$\" value-max:\"9\"`" + `
+	Names  map[string]string ` + "`json:\"names,omitempty\" key-maxLen:\"2\"`" + `
+	Parent *Order            ` + "`json:\"parent\"`" + `
+	Point  [2]int            ` + "`json:\"point\" item-min:\"0\"`" + `
+	Inline struct {
+		A int ` + "`json:\"a\" max:\"1\"`" + `
+	} ` + "`json:\"inline\"`" + `
+	Matrix [][]int ` + "`json:\"matrix,omitempty\" item-item-max:\"1\"`" + `
+	*Meta
+}
+`
+
+func TestValidators(t *testing.T) {
+	g := got.T(t)
+
+	code, err := codegen.Validators(jschema.New(""), "main", reflect.TypeOf(Base{}), reflect.TypeOf(Item{}), reflect.TypeOf(Order{}))
+	g.E(err)
+
+	g.Snapshot("validators", string(code))
+
+	out := run(g, map[string]string{
+		"types.go":      itemTypes,
+		"validators.go": string(code),
+		"main.go": `package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ysmood/jschema"
+	"github.com/ysmood/jschema/lib/test"
+)
+
+func messages(err error) []string {
+	list := []string{}
+	if errs, ok := err.(jschema.ValidationErrors); ok {
+		for _, e := range errs {
+			list = append(list, e.Keyword+" "+e.Error())
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+func main() {
+	s := jschema.New("")
+
+	price := -1.0
+	valid := Order{Items: []Item{{Name: "a", Count: 1}}, Labels: map[string]int{}, Meta: &Meta{}}
+
+	cases := []Order{
+		valid,
+		{},
+		{
+			Items:  []Item{{Base: Base{ID: "abcd"}, Name: "", Count: 0, Price: &price, Enum: test.Enum(9)}, {Name: "ABCDEF", Count: 11}, {}, {}},
+			Tags:   []string{"abc", "a"},
+			Labels: map[string]int{"A": 1, "b": 10},
+			Names:  map[string]string{"abc": "x"},
+			Parent: &Order{Items: []Item{}},
+			Point:  [2]int{-1, 1},
+			Matrix: [][]int{{1, 2}, nil},
+			Meta:   &Meta{Note: "abc"},
+		},
+	}
+	cases[2].Inline.A = 2
+
+	for i, c := range cases {
+		x, y := fmt.Sprint(messages(s.Validate(c))), fmt.Sprint(messages(c.Validate()))
+		if x != y {
+			fmt.Printf("case %d:\n%s\n%s\n", i, x, y)
+		}
+	}
+
+	fmt.Println(valid.Validate(), len(messages(cases[2].Validate())))
+}
+`,
+	})
+
+	g.Eq(out, "<nil> 30\n")
+}
+
+func TestValidatorsErr(t *testing.T) {
+	g := got.T(t)
+
+	_, err := codegen.Validators(jschema.New(""), "main", reflect.TypeOf(1))
+	g.Eq(err.Error(), "codegen: int is not a named struct")
+
+	_, err = codegen.Validators(jschema.New(""), "main", reflect.TypeOf(Item{}), reflect.TypeOf(jschema.Ref{}))
+	g.Eq(err.Error(), "codegen: jschema.Ref is not in the package of codegen_test.Item")
+}
+
+type Inner struct {
+	A int `json:"a" yaml:"a" min:"1"`
+}
+
+type Zero struct {
+	N  int      `json:"n,omitzero" min:"1"`
+	S  []string `json:"s,omitzero" minItems:"1"`
+	In Inner    `json:"in,omitzero"`
+}
+
+type Yaml struct {
+	N  int   `yaml:"n,omitempty" min:"1"`
+	In Inner `yaml:"in,omitempty"`
+}
+
+const omitTypes = `package main
+
+type Inner struct {
+	A int ` + "`json:\"a\" yaml:\"a\" min:\"1\"`" + `
+}
+
+type Zero struct {
+	N  int      ` + "`json:\"n,omitzero\" min:\"1\"`" + `
+	S  []string ` + "`json:\"s,omitzero\" minItems:\"1\"`" + `
+	In Inner    ` + "`json:\"in,omitzero\"`" + `
+}
+
+type Yaml struct {
+	N  int   ` + "`yaml:\"n,omitempty\" min:\"1\"`" + `
+	In Inner ` + "`yaml:\"in,omitempty\"`" + `
+}
+`
+
+func TestValidatorsOmit(t *testing.T) {
+	g := got.T(t)
+
+	code, err := codegen.Validators(jschema.New(""), "main", reflect.TypeOf(Inner{}), reflect.TypeOf(Zero{}))
+	g.E(err)
+
+	yaml, err := codegen.Validators(jschema.New("").WithNameTag("yaml"), "main", reflect.TypeOf(Yaml{}))
+	g.E(err)
+
+	out := run(g, map[string]string{
+		"types.go":      omitTypes,
+		"validators.go": string(code),
+		"yaml.go":       string(yaml),
+		"main.go": `package main
+
+import (
+	"fmt"
+
+	"github.com/ysmood/jschema"
+)
+
+func main() {
+	s := jschema.New("")
+
+	for _, c := range []Zero{{}, {S: []string{}}, {N: -1, In: Inner{A: -1}}} {
+		fmt.Println(s.Validate(c), c.Validate())
+	}
+
+	fmt.Println(Yaml{}.Validate(), Yaml{N: -1}.Validate())
+}
+`,
+	})
+
+	g.Eq(out, "<nil> <nil>\n"+
+		"jschema: invalid value: /s: Array must have at least 1 items jschema: invalid value: /s: Array must have at least 1 items\n"+
+		"jschema: invalid value: /n: Must be greater than or equal to 1; /in/a: Must be greater than or equal to 1 "+
+		"jschema: invalid value: /n: Must be greater than or equal to 1; /in/a: Must be greater than or equal to 1\n"+
+		"<nil> jschema: invalid value: /n: Must be greater than or equal to 1\n")
+}
@@ -0,0 +1,600 @@
+package jschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator validates the json data against a schema, it's created by [Schemas.Compile].
+// It's safe for concurrent use, the later changes of the [Schemas] won't affect it.
+type Validator struct {
+	check checker
+}
+
+// Compile precompiles the schema of the ref into a [Validator], the refs are resolved and the patterns are compiled once.
+// The [Validator.Validate] validates the json without decoding it into go values, [Schemas.ValidateJSON] uses it too.
+// Use it when the same schema validates lots of data.
+// It returns error for the formats that have no checker if the s is [Schemas.WithStrictFormats].
+func (s Schemas) Compile(ref Ref) (*Validator, error) {
+	return s.compile(&Schema{Ref: &ref})
+}
+
+func (s Schemas) compile(scm *Schema) (*Validator, error) {
+	c := &compiler{s: s, refs: map[string]*checker{}}
+
+	check, err := c.compile(scm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Validator{check: check}, nil
+}
+
+// Validate validates the json data. It returns [ValidationErrors] if the data doesn't match the schema.
+func (v *Validator) Validate(data []byte) error {
+	st := &vstate{sc: scanner{data: data}}
+
+	err := v.check(st)
+	if err == nil {
+		err = st.sc.end()
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(st.errs) > 0 {
+		return st.errs
+	}
+
+	return nil
+}
+
+// checker consumes a json value from the scanner of st and appends the violations to st,
+// the returned error is for the malformed json.
+type checker func(st *vstate) error
+
+type vstate struct {
+	sc   scanner
+	path []string
+	errs ValidationErrors
+
+	// depth is the nesting depth of the checkers, the refs and the anyOf branches don't consume the json,
+	// so it's tracked separately from the one of the scanner.
+	depth int
+
+	// score is how well the value matches the schema, it's like the one of gojsonschema to choose the closest anyOf branch.
+	score int
+}
+
+func (st *vstate) fail(keyword string, args ...interface{}) {
+	st.report(NewValidationError(st.pointer(), keyword, args...))
+}
+
+func (st *vstate) report(e *ValidationError) {
+	st.errs = append(st.errs, e)
+	st.score -= 2
+}
+
+// nest runs check one level deeper.
+func (st *vstate) nest(check checker) error {
+	if st.depth >= maxDepth {
+		return fmt.Errorf("jschema: the schema is nested deeper than %d", maxDepth)
+	}
+	st.depth++
+	err := check(st)
+	st.depth--
+	return err
+}
+
+func (st *vstate) pointer() string {
+	if len(st.path) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(st.path, "/")
+}
+
+// sub runs check on the next value with a new error list, it returns the violations and the score of the value.
+func (st *vstate) sub(check checker) (ValidationErrors, int, error) {
+	errs, score := st.errs, st.score
+	st.errs, st.score = nil, 0
+	err := check(st)
+	sub, subScore := st.errs, st.score
+	st.errs, st.score = errs, score
+	return sub, subScore, err
+}
+
+type compiler struct {
+	s    Schemas
+	refs map[string]*checker
+}
+
+// compile returns the checker of scm, the checkers of the same ref are shared, so the recursive schemas are supported.
+func (c *compiler) compile(scm *Schema) (checker, error) {
+	if scm.Ref != nil {
+		id := scm.Ref.ID
+
+		p, has := c.refs[id]
+		if !has {
+			def, has := c.s.types[id]
+			if !has {
+				return nil, fmt.Errorf("jschema: no definition for %q", id)
+			}
+
+			p = new(checker)
+			c.refs[id] = p
+
+			check, err := c.compile(def)
+			if err != nil {
+				return nil, err
+			}
+			*p = check
+		}
+
+		ref := func(st *vstate) error { return st.nest(*p) }
+
+		// The keywords beside the $ref, such as the ones from the struct tags, must pass too.
+		site := *scm
+		site.Ref = nil
+		site.Title, site.Description, site.Default, site.Examples, site.Defs = "", "", nil, nil, nil
+		if reflect.ValueOf(site).IsZero() {
+			return ref, nil
+		}
+
+		sibling, err := c.compile(&site)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(st *vstate) error {
+			start := st.sc.i
+			err := ref(st)
+			if err != nil {
+				return err
+			}
+			st.sc.i = start
+			return sibling(st)
+		}, nil
+	}
+
+	n := &node{scm: snapshot(scm)}
+	err := n.compile(c, scm)
+	if err != nil {
+		return nil, err
+	}
+
+	check := n.check
+	return func(st *vstate) error { return st.nest(check) }, nil
+}
+
+// snapshot copies the keywords of scm that the checks of [node] read,
+// so the later changes of scm won't affect the compiled node or race with it.
+func snapshot(scm *Schema) *Schema {
+	return &Schema{
+		Type:                 scm.Type,
+		Pattern:              scm.Pattern,
+		Format:               scm.Format,
+		Min:                  copyPtr(scm.Min),
+		Max:                  copyPtr(scm.Max),
+		MinLen:               copyPtr(scm.MinLen),
+		MaxLen:               copyPtr(scm.MaxLen),
+		MinItems:             copyPtr(scm.MinItems),
+		MaxItems:             copyPtr(scm.MaxItems),
+		Required:             append(Required{}, scm.Required...),
+		AdditionalProperties: copyPtr(scm.AdditionalProperties),
+	}
+}
+
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	return Ptr(*p)
+}
+
+type pattern struct {
+	re    *regexp.Regexp
+	check checker
+}
+
+type keyword struct {
+	name  string
+	value JVal
+	check KeywordChecker
+}
+
+// node is the compiled form of a schema.
+type node struct {
+	// scm is the [snapshot] of the schema.
+	scm *Schema
+
+	pattern  *regexp.Regexp
+	format   FormatChecker
+	enum     map[string]bool
+	allowed  string
+	keywords []keyword
+
+	anyOf         []checker
+	props         map[string]checker
+	required      map[string]int
+	patterns      []pattern
+	additional    checker
+	propertyNames checker
+	prefixItems   []checker
+	items         checker
+}
+
+func (n *node) compile(c *compiler, scm *Schema) error { //nolint: cyclop
+	switch scm.Type {
+	case "", TypeString, TypeNumber, TypeInteger, TypeObject, TypeArray, TypeBool, TypeNull:
+	default:
+		return fmt.Errorf("jschema: can't compile the type %q", scm.Type)
+	}
+
+	var err error
+	compile := func(scm *Schema) checker {
+		if err != nil || scm == nil {
+			return nil
+		}
+		var check checker
+		check, err = c.compile(scm)
+		return check
+	}
+
+	if scm.Pattern != "" {
+		n.pattern, err = regexp.Compile(scm.Pattern)
+		if err != nil {
+			return fmt.Errorf("jschema: invalid pattern %q: %w", scm.Pattern, err)
+		}
+	}
+
+	if scm.Format != "" {
+		n.format = c.s.formats[scm.Format]
+		if n.format == nil && c.s.strictFormats {
+			return fmt.Errorf("jschema: unknown format %q", scm.Format)
+		}
+	}
+
+	names := []string{}
+	for name := range scm.Extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if check, has := c.s.keywords[name]; has {
+			n.keywords = append(n.keywords, keyword{name, scm.Extensions[name], check})
+		}
+	}
+
+	if len(scm.Enum) > 0 {
+		n.enum = map[string]bool{}
+		list := []string{}
+		for _, v := range scm.Enum {
+			b, e := json.Marshal(v)
+			if e != nil {
+				return fmt.Errorf("jschema: invalid enum value %v: %w", v, e)
+			}
+			var x interface{}
+			_ = json.Unmarshal(b, &x)
+			n.enum[enumKey(x)] = true
+			list = append(list, string(b))
+		}
+		n.allowed = strings.Join(list, ", ")
+	}
+
+	for _, s := range scm.AnyOf {
+		n.anyOf = append(n.anyOf, compile(s))
+	}
+
+	if len(scm.Properties) > 0 {
+		n.props = map[string]checker{}
+		for name, p := range scm.Properties {
+			n.props[name] = compile(p)
+		}
+	}
+
+	if len(scm.Required) > 0 {
+		n.required = map[string]int{}
+		for i, name := range scm.Required {
+			n.required[name] = i
+		}
+	}
+
+	keys := []string{}
+	for k := range scm.PatternProperties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		re, e := regexp.Compile(k)
+		if e != nil {
+			return fmt.Errorf("jschema: invalid pattern property %q: %w", k, e)
+		}
+		n.patterns = append(n.patterns, pattern{re, compile(scm.PatternProperties[k])})
+	}
+
+	n.additional = compile(scm.AdditionalPropertiesSchema)
+	n.propertyNames = compile(scm.PropertyNames)
+
+	for _, s := range scm.PrefixItems {
+		n.prefixItems = append(n.prefixItems, compile(s))
+	}
+	n.items = compile(scm.Items)
+
+	return err
+}
+
+func (n *node) check(st *vstate) error { //nolint: cyclop
+	scm := n.scm
+
+	t, err := st.sc.kind()
+	if err != nil {
+		return err
+	}
+
+	start := st.sc.i
+
+	if t == TypeNumber && (scm.Type == TypeInteger || scm.Type == TypeNumber) {
+		// The type of a number is decided after it's read.
+		var f float64
+		f, err = st.sc.num()
+		if err != nil {
+			return err
+		}
+		if scm.Type == TypeInteger && f != math.Trunc(f) {
+			st.fail("type", scm.Type, TypeNumber)
+			return nil
+		}
+		st.sc.i = start
+	} else if scm.Type != "" && scm.Type != t {
+		given := t
+		if t == TypeNumber {
+			given = numberType(st)
+		}
+		st.fail("type", scm.Type, given)
+		st.sc.i = start
+		return st.sc.skip()
+	}
+
+	if len(n.anyOf) > 0 {
+		err = n.checkAnyOf(st, start)
+		if err != nil {
+			return err
+		}
+		st.sc.i = start
+	}
+
+	//nolint: exhaustive
+	switch t {
+	case TypeObject:
+		err = n.checkObject(st)
+	case TypeArray:
+		err = n.checkArray(st)
+	case TypeString:
+		err = n.checkString(st)
+	case TypeNumber:
+		err = n.checkNumber(st)
+	default:
+		err = st.sc.skip()
+	}
+	if err != nil {
+		return err
+	}
+
+	if n.enum != nil || n.keywords != nil {
+		var x interface{}
+		_ = json.Unmarshal(st.sc.data[start:st.sc.i], &x)
+
+		if n.enum != nil && !n.enum[enumKey(x)] {
+			st.fail("enum", n.allowed)
+		}
+
+		for _, k := range n.keywords {
+			if err := k.check(k.value, x); err != nil {
+				st.report(&ValidationError{Path: st.pointer(), Keyword: k.name, Message: err.Error()})
+			}
+		}
+	}
+
+	st.score++
+
+	return nil
+}
+
+// numberType returns the type of the next number, "integer" or "number".
+func numberType(st *vstate) SchemaType {
+	start := st.sc.i
+	f, err := st.sc.num()
+	st.sc.i = start
+	if err == nil && f == math.Trunc(f) {
+		return TypeInteger
+	}
+	return TypeNumber
+}
+
+// checkAnyOf reports the errors of the closest branch if none of the branches matches,
+// the closest one is the first one that has the highest score.
+func (n *node) checkAnyOf(st *vstate, start int) error {
+	var best ValidationErrors
+	bestScore := 0
+
+	for _, check := range n.anyOf {
+		st.sc.i = start
+
+		errs, score, err := st.sub(check)
+		if err != nil {
+			return err
+		}
+
+		if len(errs) == 0 {
+			return nil
+		}
+
+		if best == nil || score > bestScore {
+			best, bestScore = errs, score
+		}
+	}
+
+	st.fail("anyOf")
+	st.errs = append(st.errs, best...)
+	st.score += bestScore
+
+	return nil
+}
+
+func (n *node) checkObject(st *vstate) error { //nolint: cyclop
+	scm := n.scm
+	found := make([]bool, len(n.required))
+
+	err := st.sc.object(func(key string, offset int) error {
+		if i, has := n.required[key]; has {
+			found[i] = true
+			st.score++
+		}
+
+		if n.propertyNames != nil {
+			sub := &vstate{sc: scanner{data: st.sc.data[offset:st.sc.i]}, path: st.path}
+			_ = n.propertyNames(sub)
+			if len(sub.errs) > 0 {
+				st.fail("propertyNames", key)
+				st.errs = append(st.errs, sub.errs...)
+			}
+		}
+
+		var buf [2]checker
+		checks := buf[:0]
+		if check, has := n.props[key]; has {
+			checks = append(checks, check)
+		}
+		for _, p := range n.patterns {
+			if p.re.MatchString(key) {
+				checks = append(checks, p.check)
+			}
+		}
+
+		if len(checks) == 0 {
+			switch {
+			case n.additional != nil:
+				checks = append(checks, n.additional)
+			case scm.AdditionalProperties != nil && !*scm.AdditionalProperties:
+				st.fail("additionalProperties", key)
+			}
+		}
+
+		if len(checks) == 0 {
+			return st.sc.skip()
+		}
+
+		st.path = append(st.path, key)
+		start := st.sc.i
+		for _, check := range checks {
+			st.sc.i = start
+			err := check(st)
+			if err != nil {
+				return err
+			}
+		}
+		st.path = st.path[:len(st.path)-1]
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range scm.Required {
+		if !found[n.required[name]] {
+			st.fail("required", name)
+		}
+	}
+
+	return nil
+}
+
+func (n *node) checkArray(st *vstate) error {
+	scm := n.scm
+	size := 0
+
+	err := st.sc.array(func(i int) error {
+		size++
+
+		check := n.items
+		if i < len(n.prefixItems) {
+			check = n.prefixItems[i]
+		}
+		if check == nil {
+			return st.sc.skip()
+		}
+
+		st.path = append(st.path, strconv.Itoa(i))
+		err := check(st)
+		st.path = st.path[:len(st.path)-1]
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if scm.MinItems != nil && size < *scm.MinItems {
+		st.fail("minItems", *scm.MinItems)
+	}
+	if scm.MaxItems != nil && size > *scm.MaxItems {
+		st.fail("maxItems", *scm.MaxItems)
+	}
+
+	return nil
+}
+
+func (n *node) checkString(st *vstate) error {
+	scm := n.scm
+
+	str, err := st.sc.str()
+	if err != nil {
+		return err
+	}
+
+	if scm.MinLen != nil && float64(utf8.RuneCountInString(str)) < *scm.MinLen {
+		st.fail("minLength", *scm.MinLen)
+	}
+	if scm.MaxLen != nil && float64(utf8.RuneCountInString(str)) > *scm.MaxLen {
+		st.fail("maxLength", *scm.MaxLen)
+	}
+	if n.pattern != nil && !n.pattern.MatchString(str) {
+		st.fail("pattern", scm.Pattern)
+	}
+	if n.format != nil && !n.format(str) {
+		st.fail("format", scm.Format)
+	}
+
+	return nil
+}
+
+func (n *node) checkNumber(st *vstate) error {
+	scm := n.scm
+
+	f, err := st.sc.num()
+	if err != nil {
+		return err
+	}
+
+	if scm.Min != nil && f < *scm.Min {
+		st.fail("minimum", *scm.Min)
+	}
+	if scm.Max != nil && f > *scm.Max {
+		st.fail("maximum", *scm.Max)
+	}
+
+	return nil
+}
+
+// enumKey returns the key of the json value v to compare the values, the numbers of the same value have the same key.
+func enumKey(v interface{}) string {
+	b, _ := json.Marshal(v) //nolint: errchkjson
+	return string(b)
+}
@@ -0,0 +1,288 @@
+package jschema_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/xeipuuv/gojsonschema"
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+	"github.com/ysmood/jschema/jschematest"
+)
+
+type Message struct {
+	ID      string            `json:"id" format:"uuid"`
+	Kind    string            `json:"kind" enum:"[\"event\",\"metric\"]"`
+	Level   int               `json:"level" min:"0" max:"9"`
+	Score   float64           `json:"score" min:"-1" max:"1"`
+	Host    string            `json:"host" minLen:"1" maxLen:"16" pattern:"^[a-z0-9.-]+$"`
+	Tags    []string          `json:"tags" maxItems:"4"`
+	Point   [2]float64        `json:"point"`
+	Labels  map[string]string `json:"labels"`
+	Parent  *Message          `json:"parent"`
+	Replies []*Message        `json:"replies,omitempty"`
+}
+
+// gojsonschemaErrors validates the data with gojsonschema, it's the reference of the messages of the [jschema.Validator].
+func gojsonschemaErrors(g got.G, s jschema.Schemas, ref jschema.Ref, data []byte) []string {
+	res, err := gojsonschema.Validate(
+		gojsonschema.NewGoLoader(s.ToStandAlone(&jschema.Schema{Ref: &ref})),
+		gojsonschema.NewBytesLoader(data),
+	)
+	g.E(err)
+
+	keywords := map[string]string{
+		"invalid_type":                    "type",
+		"number_any_of":                   "anyOf",
+		"array_min_items":                 "minItems",
+		"array_max_items":                 "maxItems",
+		"additional_property_not_allowed": "additionalProperties",
+		"invalid_property_name":           "propertyNames",
+		"string_gte":                      "minLength",
+		"string_lte":                      "maxLength",
+		"number_gte":                      "minimum",
+		"number_lte":                      "maximum",
+	}
+
+	list := []string{}
+	for _, e := range res.Errors() {
+		keyword, has := keywords[e.Type()]
+		if !has {
+			keyword = e.Type()
+		}
+
+		path := ""
+		if e.Field() != gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+			path = "/" + strings.ReplaceAll(e.Field(), ".", "/")
+		}
+
+		list = append(list, keyword+" "+(&jschema.ValidationError{Path: path, Message: e.Description()}).Error())
+	}
+	sort.Strings(list)
+	return list
+}
+
+// errStrings returns the sorted error messages of err, the compiled validator doesn't keep the order of the errors.
+func errStrings(err error) []string {
+	list := []string{}
+	if errs, ok := err.(jschema.ValidationErrors); ok { //nolint: errorlint
+		for _, e := range errs {
+			list = append(list, e.Keyword+" "+e.Error())
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+func TestCompile(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+	s.Define(Message{})
+	ref := s.Ref(Message{})
+
+	v, err := s.Compile(ref)
+	g.E(err)
+
+	seeds, err := jschematest.Seeds(s, Message{}, 5)
+	g.E(err)
+
+	for _, seed := range seeds {
+		actual := v.Validate(seed.JSON)
+		g.Desc("%s", seed).Eq(errStrings(actual), gojsonschemaErrors(g, s, ref, seed.JSON))
+		g.Eq(errStrings(s.ValidateJSON(ref, seed.JSON)), errStrings(actual))
+		g.Eq(actual == nil, seed.Valid())
+	}
+
+	for _, data := range []string{
+		`null`,
+		`{"id":1,"kind":"x","level":1.5,"score":2,"host":"","tags":[1,2,3,4,5],"point":[1],"labels":{"a":1},` +
+			`"parent":{"id":"x"},"other":true}`,
+		`{"parent":"x","replies":[null,{}],"point":["a","b","c"],"host":"A_B"}`,
+		` { "kind" : "event" , "level" : 1 , "labels" : { "a\n" : "b" } , "point" : [ 1 , 2 ] } `,
+	} {
+		b := []byte(data)
+		g.Desc("%s", data).Eq(errStrings(v.Validate(b)), gojsonschemaErrors(g, s, ref, b))
+	}
+
+	g.Eq(v.Validate([]byte(`{"level":9}`)).Error(),
+		`jschema: invalid value: /: id is required; /: kind is required; /: score is required; /: host is required; `+
+			`/: tags is required; /: point is required; /: labels is required; /: parent is required`)
+}
+
+func TestCompileErr(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+	s.Define(Message{})
+
+	v, err := s.Compile(s.Ref(Message{}))
+	g.E(err)
+
+	for _, data := range []string{``, `{`, `{"id"}`, `{"id":"a",}`, `[1 2]`, `"a`, `01`, `1.`, `-`, `tru`, `{} x`, `"\x"`} {
+		g.Desc("%s", data).Has(v.Validate([]byte(data)).Error(), "jschema: ")
+		g.Desc("%s", data).NotNil(json.Unmarshal([]byte(data), new(interface{})))
+	}
+
+	type Any struct {
+		V interface{} `json:"v"`
+	}
+	s.Define(Any{})
+	v, err = s.Compile(s.Ref(Any{}))
+	g.E(err)
+
+	deep := func(n int) []byte {
+		return []byte(`{"v":` + strings.Repeat("[", n) + strings.Repeat("]", n) + `}`)
+	}
+	g.Nil(v.Validate(deep(9999)))
+	g.Eq(v.Validate(deep(10000)).Error(), "jschema: the json is nested deeper than 10000")
+	g.Eq(v.Validate(deep(1000000)).Error(), "jschema: the json is nested deeper than 10000")
+
+	loop := jschema.Ref{Package: "test", ID: "Loop", Name: "Loop"}
+	s.Load(loop, &jschema.Schema{AnyOf: []*jschema.Schema{{Ref: &loop}}})
+	v, err = s.Compile(loop)
+	g.E(err)
+	g.Eq(v.Validate([]byte(`1`)).Error(), "jschema: the schema is nested deeper than 10000")
+
+	_, err = s.Compile(jschema.Ref{ID: "X"})
+	g.Eq(err.Error(), `jschema: no definition for "X"`)
+
+	s.SetSchema(Message{}, &jschema.Schema{Type: jschema.TypeString, Pattern: "("})
+	_, err = s.Compile(s.Ref(Message{}))
+	g.Has(err.Error(), `jschema: invalid pattern "("`)
+}
+
+type ShortID string
+
+func TestCompileRefSiblings(t *testing.T) {
+	g := got.T(t)
+
+	type Kind string
+
+	type Item struct {
+		ID   ShortID `json:"id" maxLen:"2" pattern:"^[a-z]+$"`
+		Kind Kind    `json:"kind"`
+	}
+
+	s := jschema.New("")
+	s.Define(Item{})
+	s.PeakSchema(Item{}).Properties["kind"] = s.Const(Kind("a"))
+
+	v, err := s.Compile(s.Ref(Item{}))
+	g.E(err)
+
+	g.Nil(v.Validate([]byte(`{"id":"ab","kind":"a"}`)))
+	g.Eq(errStrings(v.Validate([]byte(`{"id":"abcdef","kind":"b"}`))), []string{
+		`enum /kind: kind must be one of the following: "a"`,
+		"maxLength /id: String length must be less than or equal to 2",
+	})
+	g.Eq(errStrings(v.Validate([]byte(`{"id":1,"kind":"a"}`))), []string{
+		"type /id: Invalid type. Expected: string, given: integer",
+	})
+}
+
+func TestCompileSnapshot(t *testing.T) {
+	g := got.T(t)
+
+	type Item struct {
+		A string   `json:"a" maxLen:"2"`
+		B []string `json:"b" maxItems:"1"`
+	}
+
+	s := jschema.New("")
+	s.Define(Item{})
+
+	v, err := s.Compile(s.Ref(Item{}))
+	g.E(err)
+
+	// The later changes of the schema list don't affect the compiled validator.
+	scm := s.PeakSchema(Item{})
+	scm.Required.Add("c")
+	scm.Properties["a"].MaxLen = jschema.Ptr(10.0)
+	scm.Properties["b"].MaxItems = jschema.Ptr(10)
+	s.Describe(Item{}, "changed")
+
+	g.Nil(v.Validate([]byte(`{"a":"ab","b":["x"]}`)))
+	g.Eq(errStrings(v.Validate([]byte(`{"a":"abc","b":["x","y"]}`))), []string{
+		"maxItems /b: Array must have at most 1 items",
+		"maxLength /a: String length must be less than or equal to 2",
+	})
+}
+
+func TestValidateJSON(t *testing.T) {
+	g := got.T(t)
+
+	type User struct {
+		Name  string      `json:"name" format:"name" x-upper:"true"`
+		Extra interface{} `json:"extra,omitempty"`
+	}
+
+	s := jschema.New("")
+	s.Define(User{})
+	ref := s.Ref(User{})
+
+	g.Nil(s.Validate(User{Name: "jo"}))
+	g.Eq(s.WithStrictFormats().Validate(User{}).Error(), `jschema: unknown format "name"`)
+
+	s.AddFormat("name", func(s string) bool { return len(s) > 1 })
+	g.E(s.AddKeyword("x-upper", func(_, data jschema.JVal) error {
+		if str, _ := data.(string); strings.ToUpper(str) != str {
+			return fmt.Errorf("Must be upper case")
+		}
+		return nil
+	}))
+
+	g.Nil(s.WithStrictFormats().Validate(User{Name: "JO"}))
+	g.Eq(s.Validate(User{Name: "j"}).Error(),
+		`jschema: invalid value: /name: Does not match format 'name'; /name: Must be upper case`)
+	g.Eq(s.ValidateJSON(ref, []byte(`{"name":"jo"}`)).Error(), `jschema: invalid value: /name: Must be upper case`)
+
+	deep := `{"name":"JO","extra":` + strings.Repeat("[", 1000000) + strings.Repeat("]", 1000000) + `}`
+	g.Eq(s.ValidateJSON(ref, []byte(deep)).Error(), "jschema: the json is nested deeper than 10000")
+}
+
+var benchMessage = []byte(`{
+	"id": "1a2b3c4d-0000-4000-8000-000000000000", "kind": "metric", "level": 3, "score": 0.5,
+	"host": "api-1.example.io", "tags": ["a", "b"], "point": [1.5, -2], "labels": {"env": "prod", "zone": "a"},
+	"parent": null, "replies": [
+		{"id": "1a2b3c4d-0000-4000-8000-000000000001", "kind": "event", "level": 1, "score": 0, "host": "b",
+		 "tags": [], "point": [0, 0], "labels": {}, "parent": null}
+	]
+}`)
+
+func BenchmarkGojsonschema(b *testing.B) {
+	s := jschema.New("")
+	s.Define(Message{})
+	ref := s.Ref(Message{})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		res, err := gojsonschema.Validate(
+			gojsonschema.NewGoLoader(s.ToStandAlone(&jschema.Schema{Ref: &ref})),
+			gojsonschema.NewBytesLoader(benchMessage),
+		)
+		if err != nil || !res.Valid() {
+			b.Fatal(err, res.Errors())
+		}
+	}
+}
+
+func BenchmarkCompiledValidate(b *testing.B) {
+	s := jschema.New("")
+	s.Define(Message{})
+
+	v, err := s.Compile(s.Ref(Message{}))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := v.Validate(benchMessage); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
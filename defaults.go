@@ -0,0 +1,420 @@
+package jschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// SetDefaults copies the non-zero fields of the struct value v into the Default of the matching properties
+// of the schema of v's type, such as:
+//
+//	s.SetDefaults(Config{Port: 8080})
+//
+// The type will be defined if it's new, v is validated against the schema before the defaults are set.
+func (s Schemas) SetDefaults(v interface{}) error {
+	scm, err := s.namedSchema(v)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("jschema: %s is not a struct", rv.Type())
+	}
+
+	err = s.Validate(rv.Interface())
+	if err != nil {
+		return err
+	}
+
+	s.eachProperty(rv, func(name string, fv reflect.Value) {
+		if p, has := scm.Properties[name]; has && !fv.IsZero() {
+			p.Default = fv.Interface()
+		}
+	})
+
+	return nil
+}
+
+// AddExample appends the json encoding of v to the Examples of the schema of v's type.
+// The type will be defined if it's new, v is validated against the schema before it's added.
+func (s Schemas) AddExample(v interface{}) error {
+	scm, err := s.namedSchema(v)
+	if err != nil {
+		return err
+	}
+
+	v = reflect.Indirect(reflect.ValueOf(v)).Interface()
+
+	err = s.Validate(v)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var example JVal
+	_ = json.Unmarshal(b, &example)
+
+	scm.Examples = append(scm.Examples, example)
+
+	return nil
+}
+
+// namedSchema defines the type of v and returns the schema of it in the schema list,
+// if v is a pointer the schema of the element type is returned.
+func (s Schemas) namedSchema(v interface{}) (*Schema, error) {
+	t := indirectType(reflect.TypeOf(v))
+
+	r := s.RefT(t)
+	if !r.Unique() {
+		return nil, fmt.Errorf("jschema: %s is not a named type", t)
+	}
+
+	s.DefineT(t)
+
+	return s.types[r.ID], nil
+}
+
+// ApplyDefaults fills the zero fields of the value that ptr points to with the Default of their schemas,
+// the nested structs, pointers, arrays, slices and map values are filled recursively.
+// The type will be defined if it's new.
+func (s Schemas) ApplyDefaults(ptr interface{}) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("jschema: ApplyDefaults requires a non-nil pointer, got %T", ptr)
+	}
+
+	return s.applyDefaults(s.DefineT(v.Elem().Type()), v.Elem())
+}
+
+func (s Schemas) applyDefaults(scm *Schema, v reflect.Value) error {
+	scm = s.resolve(scm)
+	if scm == nil {
+		return nil
+	}
+
+	//nolint: exhaustive
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return s.applyDefaults(scm, v.Elem())
+		}
+
+	case reflect.Slice, reflect.Array:
+		if scm.Items == nil {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			err := s.applyDefaults(scm.Items, v.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		item := scm.mapValue()
+		if item == nil {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			el := reflect.New(v.Type().Elem()).Elem()
+			el.Set(iter.Value())
+			err := s.applyDefaults(item, el)
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), el)
+		}
+
+	case reflect.Struct:
+		var err error
+		s.eachProperty(v, func(name string, fv reflect.Value) {
+			p, has := scm.Properties[name]
+			if !has || err != nil || !fv.CanSet() {
+				return
+			}
+
+			if d := s.defaultOf(p); d != nil && fv.IsZero() {
+				err = setJSON(fv, d)
+				return
+			}
+
+			err = s.applyDefaults(p, fv)
+		})
+		return err
+	}
+
+	return nil
+}
+
+// ApplyDefaultsJSON fills the missing properties of the json data with the Default of their schemas,
+// the schema of the data is the ref. The nested objects and arrays are filled recursively.
+// For the anyOf that isn't a nullable wrapper, the data is left untouched.
+// The members of the objects keep their order, the filled ones are appended in the order of their names,
+// and the strings are not HTML escaped.
+func (s Schemas) ApplyDefaultsJSON(ref Ref, data []byte) ([]byte, error) {
+	var raw json.RawMessage
+
+	err := json.NewDecoder(bytes.NewReader(data)).Decode(&raw)
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+
+	v, err := decodeOrdered(d)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.applyJSONDefaults(&Schema{Ref: &ref}, v)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	err = encodeOrdered(buf, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s Schemas) applyJSONDefaults(scm *Schema, v interface{}) error {
+	scm = s.resolve(scm)
+	if scm == nil {
+		return nil
+	}
+
+	switch v := v.(type) {
+	case *jsonObject:
+		names := make([]string, 0, len(scm.Properties))
+		for name := range scm.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if _, has := v.values[name]; has {
+				continue
+			}
+
+			if d := s.defaultOf(scm.Properties[name]); d != nil {
+				val, err := toJSONValue(d)
+				if err != nil {
+					return err
+				}
+				v.set(name, val)
+			}
+		}
+
+		for _, name := range v.keys {
+			val := v.values[name]
+			p := scm.Properties[name]
+			if p == nil {
+				p = scm.mapValue()
+			}
+
+			err := s.applyJSONDefaults(p, val)
+			if err != nil {
+				return err
+			}
+		}
+
+	case []interface{}:
+		for i, val := range v {
+			p := scm.Items
+			if i < len(scm.PrefixItems) {
+				p = scm.PrefixItems[i]
+			}
+
+			err := s.applyJSONDefaults(p, val)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolve follows the $ref and unwraps the nullable anyOf until the schema is concrete.
+func (s Schemas) resolve(scm *Schema) *Schema {
+	for scm != nil {
+		switch {
+		case scm.Ref != nil:
+			scm = s.types[scm.Ref.ID]
+		case len(scm.AnyOf) == 2 && scm.AnyOf[1].Type == TypeNull:
+			scm = scm.AnyOf[0]
+		default:
+			return scm
+		}
+	}
+	return nil
+}
+
+// defaultOf returns the Default of the property schema, if it's not set the Default of its definition is returned.
+func (s Schemas) defaultOf(p *Schema) JVal { //nolint: ireturn
+	if p.Default != nil {
+		return p.Default
+	}
+
+	if r := s.resolve(p); r != nil {
+		return r.Default
+	}
+
+	return nil
+}
+
+// mapValue returns the schema of the map values.
+func (s *Schema) mapValue() *Schema {
+	if s.AdditionalPropertiesSchema != nil {
+		return s.AdditionalPropertiesSchema
+	}
+	return s.PatternProperties[""]
+}
+
+// setJSON sets v to the json value val by the json encoding.
+func setJSON(v reflect.Value, val JVal) error {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	n := reflect.New(v.Type())
+
+	err = json.Unmarshal(b, n.Interface())
+	if err != nil {
+		return err
+	}
+
+	v.Set(n.Elem())
+
+	return nil
+}
+
+// toJSONValue converts val to the json value that [decodeOrdered] returns.
+func toJSONValue(val JVal) (interface{}, error) {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	return decodeOrdered(d)
+}
+
+// jsonObject is a json object that keeps the order of its members.
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// set appends the member if it's new, or replaces the value of it.
+func (o *jsonObject) set(name string, val interface{}) {
+	if _, has := o.values[name]; !has {
+		o.keys = append(o.keys, name)
+	}
+	o.values[name] = val
+}
+
+// decodeOrdered decodes the next json value of d, the objects are decoded as *jsonObject.
+func decodeOrdered(d *json.Decoder) (interface{}, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		o := &jsonObject{values: map[string]interface{}{}}
+		for d.More() {
+			name, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			val, err := decodeOrdered(d)
+			if err != nil {
+				return nil, err
+			}
+
+			o.set(name.(string), val) //nolint: forcetypeassert
+		}
+		_, err = d.Token()
+		return o, err
+
+	case json.Delim('['):
+		list := []interface{}{}
+		for d.More() {
+			val, err := decodeOrdered(d)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, val)
+		}
+		_, err = d.Token()
+		return list, err
+	}
+
+	return t, nil
+}
+
+// encodeOrdered writes v as compact json to buf, v is a value from [decodeOrdered].
+func encodeOrdered(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case *jsonObject:
+		buf.WriteByte('{')
+		for i, name := range v.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			err := encodeOrdered(buf, name)
+			if err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			err = encodeOrdered(buf, v.values[name])
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, val := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			err := encodeOrdered(buf, val)
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+
+	default:
+		e := json.NewEncoder(buf)
+		e.SetEscapeHTML(false)
+		err := e.Encode(v)
+		if err != nil {
+			return err
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package jschema_test
+
+import (
+	"testing"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+)
+
+type Server struct {
+	Host    string   `json:"host"`
+	Port    int      `json:"port" max:"65535"`
+	Tags    []string `json:"tags,omitempty"`
+	Debug   bool     `json:"debug"`
+	Timeout *int     `json:"timeout,omitempty"`
+}
+
+func TestSetDefaults(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+
+	timeout := 3
+	g.E(s.SetDefaults(&Server{Host: "localhost", Port: 8080, Timeout: &timeout}))
+
+	scm := s.PeakSchema(Server{})
+	g.Eq(scm.Properties["host"].Default, "localhost")
+	g.Eq(scm.Properties["port"].Default, 8080)
+	g.Eq(scm.Properties["timeout"].Default, &timeout)
+	g.Nil(scm.Properties["debug"].Default)
+	g.Nil(scm.Properties["tags"].Default)
+
+	err := s.SetDefaults(Server{Port: 70000})
+	g.Eq(err.Error(), "jschema: invalid value: /port: Must be less than or equal to 65535")
+	g.Eq(err.(jschema.ValidationErrors)[0].Keyword, "maximum")
+
+	g.Eq(s.SetDefaults(1).Error(), "jschema: int is not a named type")
+}
+
+func TestAddExample(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+
+	g.E(s.AddExample(Server{Host: "a", Tags: []string{"x"}}))
+	g.E(s.AddExample(&Server{Host: "b"}))
+
+	g.Eq(g.JSON(g.ToJSONString(s.PeakSchema(Server{}).Examples)), []interface{}{
+		map[string]interface{}{"debug": false, "host": "a", "port": 0.0, "tags": []interface{}{"x"}},
+		map[string]interface{}{"debug": false, "host": "b", "port": 0.0},
+	})
+
+	g.NotNil(s.AddExample(Server{Port: 70000}))
+	g.Len(s.PeakSchema(Server{}).Examples, 2)
+}
+
+type Upstream struct {
+	Name    string  `json:"name"`
+	Weight  int     `json:"weight,omitempty" default:"1"`
+	Backup  *Server `json:"backup,omitempty"`
+	Servers []Server
+}
+
+type Proxy struct {
+	Main      Upstream            `json:"main"`
+	Upstreams map[string]Upstream `json:"upstreams"`
+	Fallback  *Upstream           `json:"fallback"`
+}
+
+func TestApplyDefaults(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+	g.E(s.SetDefaults(Server{Host: "localhost", Port: 80}))
+
+	p := Proxy{
+		Main: Upstream{
+			Servers: []Server{{Port: 1}, {}},
+			Backup:  &Server{Host: "backup"},
+		},
+		Upstreams: map[string]Upstream{"a": {Weight: 2}},
+		Fallback:  &Upstream{},
+	}
+
+	g.E(s.ApplyDefaults(&p))
+
+	g.Eq(p, Proxy{
+		Main: Upstream{
+			Weight:  1,
+			Servers: []Server{{Host: "localhost", Port: 1}, {Host: "localhost", Port: 80}},
+			Backup:  &Server{Host: "backup", Port: 80},
+		},
+		Upstreams: map[string]Upstream{"a": {Weight: 2}},
+		Fallback:  &Upstream{Weight: 1},
+	})
+
+	g.Eq(s.ApplyDefaults(p).Error(), "jschema: ApplyDefaults requires a non-nil pointer, got jschema_test.Proxy")
+}
+
+func TestApplyDefaultsJSON(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+	g.E(s.SetDefaults(Server{Host: "localhost", Port: 80}))
+	s.Define(Proxy{})
+
+	out, err := s.ApplyDefaultsJSON(s.Ref(Proxy{}), []byte(`{
+		"main": {"name": "a", "Servers": [{"port": 1.50}, {"host": "h", "extra": null}]},
+		"upstreams": {"b": {"backup": {}}},
+		"fallback": null
+	}`))
+	g.E(err)
+
+	g.Eq(string(out), `{"main":{"name":"a","Servers":[{"port":1.50,"host":"localhost"},`+
+		`{"host":"h","extra":null,"port":80}],"weight":1},`+
+		`"upstreams":{"b":{"backup":{"host":"localhost","port":80},"weight":1}},"fallback":null}`)
+
+	out, err = s.ApplyDefaultsJSON(s.Ref(Server{}), []byte(`{"port": 1, "tag": "<a&b>", "host": "h"}`))
+	g.E(err)
+	g.Eq(string(out), `{"port":1,"tag":"<a&b>","host":"h"}`)
+
+	_, err = s.ApplyDefaultsJSON(s.Ref(Proxy{}), []byte(`{`))
+	g.Eq(err.Error(), "unexpected EOF")
+}
@@ -0,0 +1,104 @@
+package jschema
+
+import "fmt"
+
+// Dereference returns a copy of scm that inlines every $ref with the definition in the schema list,
+// it's for the consumers that can't resolve $ref.
+// The recursive types, such as a Node that has Children []*Node, keep the $ref at the cycle point,
+// the definitions of them are kept in the $defs of the returned schema.
+// The fields beside a $ref, such as the title and description from the struct tag, override the ones of the definition.
+// Like [Schemas.ToStandAlone], the refs without go types point to the $defs of scm first, such as the ones from [Infer].
+func (s Schemas) Dereference(scm *Schema) (*Schema, error) {
+	return s.dereference(scm, false)
+}
+
+// DereferenceStrict is like [Schemas.Dereference] but returns an error when there's a recursive type,
+// so the returned schema never has $ref.
+func (s Schemas) DereferenceStrict(scm *Schema) (*Schema, error) {
+	return s.dereference(scm, true)
+}
+
+func (s Schemas) dereference(scm *Schema, strict bool) (*Schema, error) {
+	d := &dereferencer{s: s, local: scm.Defs, strict: strict, cycles: map[string]Ref{}}
+
+	out := scm.Clone()
+	out.Defs = nil
+
+	err := d.visit(out, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defs := Types{}
+	for len(defs) < len(d.cycles) {
+		for id, ref := range d.cycles {
+			if _, has := defs[id]; has {
+				continue
+			}
+
+			def := &Schema{Ref: &ref}
+			err := d.visit(def, nil)
+			if err != nil {
+				return nil, err
+			}
+			defs[id] = def
+		}
+	}
+
+	if len(defs) > 0 {
+		out.Defs = defs
+	}
+
+	out.ChangeDefs("#/$defs")
+
+	return out, nil
+}
+
+type dereferencer struct {
+	s      Schemas
+	local  Types
+	strict bool
+	cycles map[string]Ref
+}
+
+// visit inlines the $refs of scm recursively, the stack is the IDs of the definitions that are being inlined.
+func (d *dereferencer) visit(scm *Schema, stack []string) error {
+	if scm.Ref != nil {
+		id := scm.Ref.ID
+
+		for _, parent := range stack {
+			if parent == id {
+				if d.strict {
+					return fmt.Errorf("jschema: recursive reference %q", id)
+				}
+				d.cycles[id] = *scm.Ref
+				return nil
+			}
+		}
+
+		def, has := d.local[id]
+		if !has || scm.Ref.Unique() {
+			def, has = d.s.types[id]
+		}
+		if !has {
+			return fmt.Errorf("jschema: no definition for %q", id)
+		}
+
+		site := *scm
+		site.Ref = nil
+		def = def.Clone()
+		def.merge(&site)
+		*scm = *def
+
+		stack = append(stack[:len(stack):len(stack)], id)
+	}
+
+	for _, ss := range scm.subs() {
+		err := d.visit(ss, stack)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
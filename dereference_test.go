@@ -0,0 +1,116 @@
+package jschema_test
+
+import (
+	"testing"
+
+	"github.com/xeipuuv/gojsonschema"
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+)
+
+func TestDereference(t *testing.T) {
+	g := got.T(t)
+
+	type A struct {
+		ID int `json:"id"`
+	}
+
+	type B struct {
+		A  A  `json:"a" description:"the a"`
+		A2 *A `json:"a2"`
+	}
+
+	type Node struct {
+		B        B       `json:"b"`
+		Children []*Node `json:"children"`
+	}
+
+	s := jschema.New("")
+
+	scm, err := s.DereferenceStrict(s.Define(B{}))
+	g.E(err)
+	g.Eq(g.JSON(scm.String()), map[string]interface{}{
+		"additionalProperties": false,
+		"description":          "github.com/ysmood/jschema_test.B",
+		"properties": map[string]interface{}{
+			"a": map[string]interface{}{
+				"additionalProperties": false,
+				"description":          "the a",
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{"type": "integer"},
+				},
+				"required": []interface{}{"id"},
+				"title":    "A",
+				"type":     "object",
+			},
+			"a2": map[string]interface{}{
+				"anyOf": []interface{}{
+					map[string]interface{}{
+						"additionalProperties": false,
+						"description":          "github.com/ysmood/jschema_test.A",
+						"properties": map[string]interface{}{
+							"id": map[string]interface{}{"type": "integer"},
+						},
+						"required": []interface{}{"id"},
+						"title":    "A",
+						"type":     "object",
+					},
+					map[string]interface{}{"type": "null"},
+				},
+			},
+		},
+		"required": []interface{}{"a", "a2"},
+		"title":    "B",
+		"type":     "object",
+	})
+	g.Eq(s.Get("B").Properties["a"].Ref.ID, "A")
+
+	_, err = s.DereferenceStrict(s.Define(Node{}))
+	g.Eq(err.Error(), `jschema: recursive reference "Node"`)
+
+	scm, err = s.Dereference(s.Define([]Node{}))
+	g.E(err)
+	g.Eq(scm.Items.Title, "Node")
+	g.Eq(scm.Items.Properties["b"].Properties["a"].Description, "the a")
+	g.Eq(scm.Items.Properties["children"].Items.AnyOf[0].Ref.ID, "Node")
+	g.Eq(scm.Items.Properties["children"].Items.AnyOf[0].Ref.Defs, "#/$defs")
+	g.Len(scm.Defs, 1)
+	g.Eq(scm.Defs["Node"].Properties["b"].Title, "B")
+	g.Eq(scm.Defs["Node"].Properties["children"].Items.AnyOf[0].Ref.ID, "Node")
+
+	res, err := gojsonschema.Validate(gojsonschema.NewGoLoader(scm), gojsonschema.NewStringLoader(
+		`[{"b":{"a":{"id":1},"a2":null},"children":[{"b":{"a":{"id":1},"a2":null},"children":[]}]}]`,
+	))
+	g.E(err)
+	g.Desc("%v", res.Errors()).True(res.Valid())
+
+	_, err = s.Dereference(&jschema.Schema{Ref: &jschema.Ref{ID: "Nope"}})
+	g.Eq(err.Error(), `jschema: no definition for "Nope"`)
+}
+
+func TestDereferenceLocalDefs(t *testing.T) {
+	g := got.T(t)
+
+	type A struct {
+		ID int `json:"id"`
+	}
+
+	type B struct {
+		A A `json:"a"`
+	}
+
+	s := jschema.New("")
+	s.Define(B{})
+
+	scm, err := jschema.Infer([]byte(`{"a": {"name": "x"}}`))
+	g.E(err)
+	scm.Defs = jschema.Types{"A": scm.Properties["a"]}
+	scm.Properties["a"] = &jschema.Schema{Ref: &jschema.Ref{ID: "A"}}
+	scm.Properties["b"] = s.Define(B{})
+
+	out, err := s.Dereference(scm)
+	g.E(err)
+	g.Eq(out.Properties["a"].Properties["name"].Type, jschema.TypeString)
+	g.Eq(out.Properties["b"].Properties["a"].Properties["id"].Type, jschema.TypeInteger)
+	g.Nil(out.Defs)
+}
@@ -0,0 +1,539 @@
+// Package diff compares two versions of json schemas and classifies the changes.
+//
+// A change breaks writers if a value that is valid for the old schema may be invalid for the new one,
+// such as the clients that keep sending the requests of the old version.
+// A change breaks readers if a value that is valid for the new schema may be invalid for the old one,
+// such as the clients that keep parsing the responses with the old version.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ysmood/jschema"
+)
+
+// Kind is the kind of a [Change].
+type Kind string
+
+const (
+	// KindAdded means the keyword, value, property or definition is added.
+	KindAdded Kind = "added"
+
+	// KindRemoved means the keyword, value, property or definition is removed.
+	KindRemoved Kind = "removed"
+
+	// KindTightened means the new schema accepts less values.
+	KindTightened Kind = "tightened"
+
+	// KindLoosened means the new schema accepts more values.
+	KindLoosened Kind = "loosened"
+
+	// KindChanged means the new schema accepts different values.
+	KindChanged Kind = "changed"
+)
+
+// Change is a difference between the old and new schema.
+type Change struct {
+	// Path is the json pointer of the changed schema, the first segment is the definition ID,
+	// such as "Node/properties/name".
+	Path    string       `json:"path"`
+	Keyword string       `json:"keyword"`
+	Kind    Kind         `json:"kind"`
+	Old     jschema.JVal `json:"old,omitempty"`
+	New     jschema.JVal `json:"new,omitempty"`
+
+	BreaksReaders bool `json:"breaksReaders"`
+	BreaksWriters bool `json:"breaksWriters"`
+}
+
+// String returns the human readable description of the change.
+func (c Change) String() string {
+	tags := []string{}
+	if c.BreaksReaders {
+		tags = append(tags, "reader")
+	}
+	if c.BreaksWriters {
+		tags = append(tags, "writer")
+	}
+	if len(tags) == 0 {
+		tags = append(tags, "compatible")
+	}
+
+	msg := fmt.Sprintf("[%s] %s: %s %s", strings.Join(tags, ","), c.Path, c.Keyword, c.Kind)
+
+	switch {
+	case c.Old != nil && c.New != nil:
+		msg += fmt.Sprintf(" from %s to %s", toString(c.Old), toString(c.New))
+	case c.Old != nil:
+		msg += " " + toString(c.Old)
+	case c.New != nil:
+		msg += " " + toString(c.New)
+	}
+
+	return msg
+}
+
+// Report is the result of a comparison, the json of it is the machine-readable report.
+type Report struct {
+	Changes []Change `json:"changes"`
+}
+
+// BreaksReaders returns true if any change breaks the readers.
+func (r *Report) BreaksReaders() bool {
+	for _, c := range r.Changes {
+		if c.BreaksReaders {
+			return true
+		}
+	}
+	return false
+}
+
+// BreaksWriters returns true if any change breaks the writers.
+func (r *Report) BreaksWriters() bool {
+	for _, c := range r.Changes {
+		if c.BreaksWriters {
+			return true
+		}
+	}
+	return false
+}
+
+// Breaking returns true if any change breaks the readers or writers.
+func (r *Report) Breaking() bool {
+	return r.BreaksReaders() || r.BreaksWriters()
+}
+
+// String returns the human readable summary of the report.
+func (r *Report) String() string {
+	readers, writers := 0, 0
+	for _, c := range r.Changes {
+		if c.BreaksReaders {
+			readers++
+		}
+		if c.BreaksWriters {
+			writers++
+		}
+	}
+
+	lines := []string{fmt.Sprintf(
+		"%d changes, %d break readers, %d break writers", len(r.Changes), readers, writers,
+	)}
+	for _, c := range r.Changes {
+		lines = append(lines, c.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Schemas compares the definitions of two schema lists, from is the old version and to is the new one.
+func Schemas(from, to jschema.Schemas) *Report {
+	return Types(from.JSON(), to.JSON())
+}
+
+// Types compares two definition lists, such as the ones decoded from the json files of [jschema.Schemas.String].
+// The $refs are resolved by the IDs in the lists.
+func Types(from, to jschema.Types) *Report {
+	d := &differ{from: from, to: to, seen: map[[2]string]bool{}}
+	d.types("", from, to)
+	return &Report{Changes: d.changes}
+}
+
+// Schema compares two schemas, the $refs are resolved by the $defs of them,
+// such as the ones returned by [jschema.Schemas.ToStandAlone].
+func Schema(from, to *jschema.Schema) *Report {
+	d := &differ{from: from.Defs, to: to.Defs, seen: map[[2]string]bool{}}
+	d.schema("", from, to)
+	d.types("$defs/", from.Defs, to.Defs)
+	return &Report{Changes: d.changes}
+}
+
+type differ struct {
+	from, to jschema.Types
+	seen     map[[2]string]bool
+	changes  []Change
+}
+
+func (d *differ) add(c Change) {
+	d.changes = append(d.changes, c)
+}
+
+func (d *differ) types(prefix string, from, to jschema.Types) {
+	for _, id := range keys(from, to) {
+		o, n := from[id], to[id]
+
+		switch {
+		case n == nil:
+			d.add(Change{Path: prefix + id, Keyword: "$defs", Kind: KindRemoved, BreaksReaders: true, BreaksWriters: true})
+		case o == nil:
+			d.add(Change{Path: prefix + id, Keyword: "$defs", Kind: KindAdded})
+		default:
+			d.schema(prefix+id, o, n)
+		}
+	}
+}
+
+func (d *differ) schema(path string, o, n *jschema.Schema) { //nolint: cyclop
+	if o.Ref != nil && n.Ref != nil {
+		// The same definition is compared by the definition list.
+		if o.Ref.ID == n.Ref.ID {
+			return
+		}
+
+		key := [2]string{o.Ref.ID, n.Ref.ID}
+		if d.seen[key] {
+			return
+		}
+		d.seen[key] = true
+	}
+
+	o, n = resolve(d.from, o), resolve(d.to, n)
+
+	on, oNullable := nonNull(o)
+	nn, nNullable := nonNull(n)
+	if oNullable != nNullable {
+		c := Change{Path: path, Keyword: "type", BreaksReaders: nNullable, BreaksWriters: oNullable}
+		if nNullable {
+			c.Kind, c.New = KindAdded, jschema.TypeNull
+		} else {
+			c.Kind, c.Old = KindRemoved, jschema.TypeNull
+		}
+		d.add(c)
+	}
+	if oNullable || nNullable {
+		d.schema(path, on, nn)
+		return
+	}
+
+	d.typ(path, o.Type, n.Type)
+	d.enum(path, o.Enum, n.Enum)
+
+	d.bound(path, "maximum", o.Max, n.Max, true)
+	d.bound(path, "minimum", o.Min, n.Min, false)
+	d.bound(path, "maxLength", o.MaxLen, n.MaxLen, true)
+	d.bound(path, "minLength", o.MinLen, n.MinLen, false)
+	d.bound(path, "maxItems", intToFloat(o.MaxItems), intToFloat(n.MaxItems), true)
+	d.bound(path, "minItems", intToFloat(o.MinItems), intToFloat(n.MinItems), false)
+
+	d.str(path, "pattern", o.Pattern, n.Pattern)
+	d.str(path, "format", o.Format, n.Format)
+
+	d.required(path, o.Required, n.Required)
+	d.properties(path, o, n)
+	d.additionalProperties(path, o, n)
+
+	d.list(path+"/anyOf", "anyOf", o.AnyOf, n.AnyOf, false)
+	d.list(path+"/prefixItems", "prefixItems", o.PrefixItems, n.PrefixItems, true)
+	d.sub(path+"/items", "items", o.Items, n.Items)
+	d.sub(path+"/propertyNames", "propertyNames", o.PropertyNames, n.PropertyNames)
+}
+
+func (d *differ) typ(path string, o, n jschema.SchemaType) {
+	if o == n {
+		return
+	}
+
+	c := Change{Path: path, Keyword: "type"}
+	if o != "" {
+		c.Old = o
+	}
+	if n != "" {
+		c.New = n
+	}
+
+	switch {
+	case o == "", o == jschema.TypeNumber && n == jschema.TypeInteger:
+		c.Kind, c.BreaksWriters = KindTightened, true
+	case n == "", o == jschema.TypeInteger && n == jschema.TypeNumber:
+		c.Kind, c.BreaksReaders = KindLoosened, true
+	default:
+		c.Kind, c.BreaksReaders, c.BreaksWriters = KindChanged, true, true
+	}
+
+	d.add(c)
+}
+
+func (d *differ) enum(path string, o, n []jschema.JVal) {
+	switch {
+	case len(o) == 0 && len(n) == 0:
+		return
+	case len(o) == 0:
+		d.add(Change{Path: path, Keyword: "enum", Kind: KindTightened, New: n, BreaksWriters: true})
+		return
+	case len(n) == 0:
+		d.add(Change{Path: path, Keyword: "enum", Kind: KindLoosened, Old: o, BreaksReaders: true})
+		return
+	}
+
+	removed := diffValues(o, n)
+	added := diffValues(n, o)
+
+	for _, v := range removed {
+		d.add(Change{Path: path, Keyword: "enum", Kind: KindRemoved, Old: v, BreaksWriters: true})
+	}
+	for _, v := range added {
+		d.add(Change{Path: path, Keyword: "enum", Kind: KindAdded, New: v, BreaksReaders: true})
+	}
+}
+
+// bound compares the limit keywords, upper is true for the keywords like "maximum".
+func (d *differ) bound(path, keyword string, o, n *float64, upper bool) {
+	if o == nil && n == nil || o != nil && n != nil && *o == *n {
+		return
+	}
+
+	c := Change{Path: path, Keyword: keyword}
+	if o != nil {
+		c.Old = *o
+	}
+	if n != nil {
+		c.New = *n
+	}
+
+	tighter := o == nil || n != nil && (*n < *o) == upper
+
+	if tighter {
+		c.Kind, c.BreaksWriters = KindTightened, true
+	} else {
+		c.Kind, c.BreaksReaders = KindLoosened, true
+	}
+
+	d.add(c)
+}
+
+func (d *differ) str(path, keyword, o, n string) {
+	if o == n {
+		return
+	}
+
+	c := Change{Path: path, Keyword: keyword}
+	if o != "" {
+		c.Old = o
+	}
+	if n != "" {
+		c.New = n
+	}
+
+	switch {
+	case o == "":
+		c.Kind, c.BreaksWriters = KindAdded, true
+	case n == "":
+		c.Kind, c.BreaksReaders = KindRemoved, true
+	default:
+		c.Kind, c.BreaksReaders, c.BreaksWriters = KindChanged, true, true
+	}
+
+	d.add(c)
+}
+
+func (d *differ) required(path string, o, n jschema.Required) {
+	for _, name := range n {
+		if !o.Has(name) {
+			d.add(Change{Path: path, Keyword: "required", Kind: KindAdded, New: name, BreaksWriters: true})
+		}
+	}
+	for _, name := range o {
+		if !n.Has(name) {
+			d.add(Change{Path: path, Keyword: "required", Kind: KindRemoved, Old: name, BreaksReaders: true})
+		}
+	}
+}
+
+func (d *differ) properties(path string, o, n *jschema.Schema) {
+	for _, name := range keys(o.Properties, n.Properties) {
+		op, np := o.Properties[name], n.Properties[name]
+		p := path + "/properties/" + name
+
+		switch {
+		case np == nil:
+			d.add(Change{
+				Path: p, Keyword: "properties", Kind: KindRemoved, Old: name,
+				BreaksReaders: true, BreaksWriters: closed(n),
+			})
+		case op == nil:
+			d.add(Change{
+				Path: p, Keyword: "properties", Kind: KindAdded, New: name,
+				BreaksReaders: closed(o),
+			})
+		default:
+			d.schema(p, op, np)
+		}
+	}
+
+	for _, pattern := range keys(o.PatternProperties, n.PatternProperties) {
+		if pattern == "" {
+			continue
+		}
+
+		op, np := o.PatternProperties[pattern], n.PatternProperties[pattern]
+		p := path + "/patternProperties/" + pattern
+
+		switch {
+		case np == nil:
+			d.add(Change{Path: p, Keyword: "patternProperties", Kind: KindRemoved, Old: pattern, BreaksWriters: true})
+		case op == nil:
+			d.add(Change{Path: p, Keyword: "patternProperties", Kind: KindAdded, New: pattern, BreaksReaders: true})
+		default:
+			d.schema(p, op, np)
+		}
+	}
+}
+
+func (d *differ) additionalProperties(path string, o, n *jschema.Schema) {
+	oc, nc := catchAll(o), catchAll(n)
+
+	switch op, np := openness(o), openness(n); {
+	case oc != nil && nc != nil:
+		d.schema(path+"/additionalProperties", oc, nc)
+	case op < np:
+		d.add(Change{Path: path, Keyword: "additionalProperties", Kind: KindLoosened, BreaksReaders: true})
+	case op > np:
+		d.add(Change{Path: path, Keyword: "additionalProperties", Kind: KindTightened, BreaksWriters: true})
+	}
+}
+
+// list compares the schemas by index, if positional is false an added schema only breaks readers,
+// a removed one only breaks writers, such as the anyOf.
+func (d *differ) list(path, keyword string, o, n []*jschema.Schema, positional bool) {
+	for i := 0; i < len(o) || i < len(n); i++ {
+		p := fmt.Sprintf("%s/%d", path, i)
+
+		switch {
+		case i >= len(n):
+			d.add(Change{
+				Path: p, Keyword: keyword, Kind: KindRemoved,
+				BreaksReaders: positional, BreaksWriters: true,
+			})
+		case i >= len(o):
+			d.add(Change{
+				Path: p, Keyword: keyword, Kind: KindAdded,
+				BreaksReaders: true, BreaksWriters: positional,
+			})
+		default:
+			d.schema(p, o[i], n[i])
+		}
+	}
+}
+
+func (d *differ) sub(path, keyword string, o, n *jschema.Schema) {
+	switch {
+	case o == nil && n == nil:
+	case n == nil:
+		d.add(Change{Path: path, Keyword: keyword, Kind: KindRemoved, BreaksReaders: true})
+	case o == nil:
+		d.add(Change{Path: path, Keyword: keyword, Kind: KindAdded, BreaksWriters: true})
+	default:
+		d.schema(path, o, n)
+	}
+}
+
+// resolve returns the definition of the $ref of scm, the fields beside the $ref override the definition's.
+func resolve(defs jschema.Types, scm *jschema.Schema) *jschema.Schema {
+	if scm.Ref == nil {
+		return scm
+	}
+
+	def, has := defs[scm.Ref.ID]
+	if !has {
+		return scm
+	}
+
+	out := *def
+	dst := reflect.ValueOf(&out).Elem()
+	src := reflect.ValueOf(scm).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		if f := src.Field(i); !f.IsZero() && src.Type().Field(i).Name != "Ref" {
+			dst.Field(i).Set(f)
+		}
+	}
+
+	return &out
+}
+
+// nonNull returns the non-null schema if scm is the anyOf a schema and null.
+func nonNull(scm *jschema.Schema) (*jschema.Schema, bool) {
+	if len(scm.AnyOf) != 2 {
+		return scm, false
+	}
+
+	for i, s := range scm.AnyOf {
+		if s.Type == jschema.TypeNull && s.Ref == nil {
+			return scm.AnyOf[1-i], true
+		}
+	}
+
+	return scm, false
+}
+
+// closed returns true if scm doesn't allow the properties that are not listed.
+func closed(scm *jschema.Schema) bool {
+	return scm.AdditionalProperties != nil && !*scm.AdditionalProperties &&
+		scm.AdditionalPropertiesSchema == nil && len(scm.PatternProperties) == 0
+}
+
+// openness ranks how scm allows the properties that are not listed,
+// 0 for none, 1 for the ones that match the catch-all schema, 2 for any.
+func openness(scm *jschema.Schema) int {
+	switch {
+	case closed(scm):
+		return 0
+	case catchAll(scm) != nil:
+		return 1
+	}
+	return 2
+}
+
+// catchAll returns the schema of all the properties that are not listed,
+// the patternProperties "" is the same as the additionalProperties schema.
+func catchAll(scm *jschema.Schema) *jschema.Schema {
+	if p, has := scm.PatternProperties[""]; has {
+		return p
+	}
+	return scm.AdditionalPropertiesSchema
+}
+
+func intToFloat(i *int) *float64 {
+	if i == nil {
+		return nil
+	}
+	f := float64(*i)
+	return &f
+}
+
+// diffValues returns the values of a that are not in b.
+func diffValues(a, b []jschema.JVal) []jschema.JVal {
+	has := map[string]bool{}
+	for _, v := range b {
+		has[toString(v)] = true
+	}
+
+	list := []jschema.JVal{}
+	for _, v := range a {
+		if !has[toString(v)] {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func keys[T any](a, b map[string]T) []string {
+	list := []string{}
+	for k := range a {
+		list = append(list, k)
+	}
+	for k := range b {
+		if _, has := a[k]; !has {
+			list = append(list, k)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+func toString(v jschema.JVal) string {
+	b, _ := json.Marshal(v) //nolint: errchkjson
+	return string(b)
+}
@@ -0,0 +1,158 @@
+package diff_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+	"github.com/ysmood/jschema/diff"
+)
+
+func oldSchemas() jschema.Schemas {
+	type Address struct {
+		City string `json:"city"`
+	}
+
+	type User struct {
+		ID      int      `json:"id" max:"100"`
+		Name    string   `json:"name,omitempty" maxLen:"10"`
+		Age     int      `json:"age"`
+		Role    string   `json:"role"`
+		Tags    []string `json:"tags"`
+		Address Address  `json:"address"`
+		Meta    map[string]int
+	}
+
+	s := jschema.New("")
+	s.Define(User{})
+	s.Get("User").Properties["role"].Enum = jschema.ToJValList("admin", "guest")
+	return s
+}
+
+func newSchemas() jschema.Schemas {
+	type Address struct {
+		City string `json:"city" pattern:"^[A-Z]"`
+		Zip  string `json:"zip,omitempty"`
+	}
+
+	type User struct {
+		ID      float64 `json:"id" max:"10"`
+		Name    string  `json:"name" maxLen:"20"`
+		Role    string  `json:"role"`
+		Tags    *[]int  `json:"tags"`
+		Address Address `json:"address"`
+		Meta    map[string]int
+		Extra   bool `json:"extra,omitempty"`
+	}
+
+	s := jschema.New("")
+	s.Define(User{})
+	s.Get("User").Properties["role"].Enum = jschema.ToJValList("admin", "owner")
+	return s
+}
+
+func TestSchemas(t *testing.T) {
+	g := got.T(t)
+
+	r := diff.Schemas(oldSchemas(), newSchemas())
+
+	g.True(r.Breaking())
+	g.True(r.BreaksReaders())
+	g.True(r.BreaksWriters())
+
+	g.Eq(r.String(), `13 changes, 9 break readers, 6 break writers
+[writer] Address/properties/city: pattern added "^[A-Z]"
+[reader] Address/properties/zip: properties added "zip"
+[writer] User: required added "name"
+[reader] User: required removed "age"
+[reader,writer] User/properties/age: properties removed "age"
+[reader] User/properties/extra: properties added "extra"
+[reader] User/properties/id: type loosened from "integer" to "number"
+[writer] User/properties/id: maximum tightened from 100 to 10
+[reader] User/properties/name: maxLength loosened from 10 to 20
+[writer] User/properties/role: enum removed "guest"
+[reader] User/properties/role: enum added "owner"
+[reader] User/properties/tags: type added "null"
+[reader,writer] User/properties/tags/items: type changed from "string" to "integer"`)
+
+	b, err := json.Marshal(r)
+	g.E(err)
+	g.Eq(g.JSON(b).(map[string]interface{})["changes"].([]interface{})[0], map[string]interface{}{
+		"path":          "Address/properties/city",
+		"keyword":       "pattern",
+		"kind":          "added",
+		"new":           "^[A-Z]",
+		"breaksReaders": false,
+		"breaksWriters": true,
+	})
+
+	g.False(diff.Schemas(oldSchemas(), oldSchemas()).Breaking())
+	g.Eq(diff.Schemas(oldSchemas(), oldSchemas()).String(), "0 changes, 0 break readers, 0 break writers")
+}
+
+func TestSchema(t *testing.T) {
+	g := got.T(t)
+
+	type Node struct {
+		Value    int     `json:"value"`
+		Children []*Node `json:"children"`
+	}
+
+	o := jschema.New("")
+	n := jschema.New("").WithMapAdditionalProperties()
+
+	r := diff.Schema(o.SchemaT(jschemaType[[]Node]()), n.SchemaT(jschemaType[[]Node]()))
+	g.False(r.Breaking())
+
+	r = diff.Schema(o.SchemaT(jschemaType[[]Node]()), n.SchemaT(jschemaType[[]*Node]()))
+	g.Eq(r.String(), `1 changes, 1 break readers, 0 break writers
+[reader] /items: type added "null"`)
+
+	r = diff.Schema(o.SchemaT(jschemaType[map[string]int]()), n.SchemaT(jschemaType[map[string]int]()))
+	g.False(r.Breaking())
+
+	r = diff.Schema(o.SchemaT(jschemaType[map[string]int]()), n.SchemaT(jschemaType[map[string]float64]()))
+	g.Eq(r.String(), `1 changes, 1 break readers, 0 break writers
+[reader] /additionalProperties: type loosened from "integer" to "number"`)
+
+	r = diff.Schema(o.SchemaT(jschemaType[struct{}]()), o.SchemaT(jschemaType[map[string]int]()))
+	g.Eq(r.String(), `1 changes, 1 break readers, 0 break writers
+[reader] : additionalProperties loosened`)
+
+	closed := &jschema.Schema{Type: jschema.TypeObject, AdditionalProperties: new(bool)}
+	open := &jschema.Schema{Type: jschema.TypeObject}
+	additional := &jschema.Schema{Type: jschema.TypeObject, AdditionalPropertiesSchema: &jschema.Schema{Type: jschema.TypeInteger}}
+	pattern := &jschema.Schema{Type: jschema.TypeObject, PatternProperties: jschema.Properties{"": {Type: jschema.TypeInteger}}}
+	for _, c := range []struct {
+		o, n *jschema.Schema
+		out  string
+	}{
+		{additional, closed, "[writer] : additionalProperties tightened"},
+		{pattern, closed, "[writer] : additionalProperties tightened"},
+		{open, closed, "[writer] : additionalProperties tightened"},
+		{open, additional, "[writer] : additionalProperties tightened"},
+		{closed, additional, "[reader] : additionalProperties loosened"},
+		{closed, pattern, "[reader] : additionalProperties loosened"},
+		{closed, open, "[reader] : additionalProperties loosened"},
+		{pattern, open, "[reader] : additionalProperties loosened"},
+		{pattern, additional, ""},
+	} {
+		changes := []string{}
+		for _, change := range diff.Schema(c.o, c.n).Changes {
+			changes = append(changes, change.String())
+		}
+		g.Desc("%s -> %s", c.o, c.n).Eq(strings.Join(changes, "\n"), c.out)
+	}
+
+	r = diff.Types(jschema.Types{"A": {}}, jschema.Types{"B": {}})
+	g.Eq(r.String(), `2 changes, 1 break readers, 1 break writers
+[reader,writer] A: $defs removed
+[compatible] B: $defs added`)
+}
+
+func jschemaType[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
@@ -0,0 +1,97 @@
+package jschema
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FormatChecker reports whether the string is in the format.
+type FormatChecker func(s string) bool
+
+// Formats is the built-in format checkers, every new [Schemas] starts with a copy of it.
+// Use [Schemas.AddFormat] to register the formats of a [Schemas].
+var Formats = map[string]FormatChecker{
+	"email":     isEmail,
+	"uuid":      regUUID.MatchString,
+	"date-time": isTime(time.RFC3339),
+	"date":      isTime("2006-01-02"),
+	"time":      isTime("15:04:05Z07:00"),
+	"ipv4":      isIPv4,
+	"ipv6":      isIPv6,
+	"uri":       isURI,
+	"hostname":  isHostname,
+}
+
+// KeywordChecker validates the json value data against the value of a custom keyword, such as the true of "x-even".
+// Both are [JVal] decoded by [json.Unmarshal], such as a number is a float64. The returned error is the message of the violation.
+type KeywordChecker func(value, data JVal) error
+
+// KeywordPrefix is the prefix of the custom keywords, such as "x-unit".
+// The custom keywords of a schema are in the [Schema.Extensions].
+const KeywordPrefix = "x-"
+
+// AddFormat registers the checker of the format name for the validation, it overrides the built-in one of the same name.
+func (s Schemas) AddFormat(name string, check FormatChecker) {
+	s.formats[name] = check
+}
+
+// AddKeyword registers the checker of the custom keyword name for the validation, the name must start with the [KeywordPrefix].
+// The custom keywords that have no checker are annotations only.
+func (s Schemas) AddKeyword(name string, check KeywordChecker) error {
+	if !strings.HasPrefix(name, KeywordPrefix) {
+		return fmt.Errorf("jschema: the custom keyword %q must start with %q", name, KeywordPrefix)
+	}
+
+	s.keywords[name] = check
+
+	return nil
+}
+
+// WithStrictFormats returns a copy of s that rejects the schemas that have the formats without checker,
+// such as the `format:"name"`: [Schemas.Compile], [Schemas.Validate] and [Schemas.ValidateJSON] return the
+// "jschema: unknown format" error before any data is checked, it's not a [ValidationError].
+// By default the unknown formats are annotations only.
+func (s Schemas) WithStrictFormats() Schemas {
+	s.strictFormats = true
+	return s
+}
+
+func isTime(layout string) FormatChecker {
+	return func(s string) bool {
+		_, err := time.Parse(layout, s)
+		return err == nil
+	}
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+func isIPv4(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && !strings.Contains(s, ":")
+}
+
+func isIPv6(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && strings.Contains(s, ":")
+}
+
+func isURI(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && !strings.Contains(s, `\`)
+}
+
+var regUUID = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+var regHostname = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
+func isHostname(s string) bool {
+	return len(s) <= 253 && regHostname.MatchString(s)
+}
@@ -0,0 +1,148 @@
+package jschema_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+)
+
+func TestFormats(t *testing.T) {
+	g := got.T(t)
+
+	for format, list := range map[string][2][]string{
+		"email":     {{"a@b.io", "a.b+c@d-e.io"}, {"a", "a@", "A <a@b.io>"}},
+		"uuid":      {{"1a2b3c4d-0000-4000-8000-00000000000F"}, {"1a2b3c4d-0000-4000-8000", "1a2b3c4d00004000800000000000000f"}},
+		"date-time": {{"2024-01-02T03:04:05Z", "2024-01-02T03:04:05.1+08:00"}, {"2024-01-02", "03:04:05Z", "2024-01-02 03:04:05Z"}},
+		"date":      {{"2024-01-02"}, {"2024-1-2", "2024-01-02T03:04:05Z"}},
+		"time":      {{"03:04:05Z", "03:04:05+08:00"}, {"03:04", "25:00:00Z"}},
+		"ipv4":      {{"127.0.0.1"}, {"::1", "256.0.0.1", "::ffff:127.0.0.1"}},
+		"ipv6":      {{"::1", "::ffff:127.0.0.1", "2001:db8::1"}, {"127.0.0.1", "2001:db8::g"}},
+		"uri":       {{"https://a.io/b?c=1", "mailto:a@b.io"}, {"/a/b", "a.io", `http://a\b`}},
+		"hostname":  {{"a", "a-b.c1.io", strings.Repeat("a", 63)}, {"-a", "a_b", "a..b", strings.Repeat("a", 64)}},
+	} {
+		check := jschema.Formats[format]
+		for _, v := range list[0] {
+			g.Desc("%s %s", format, v).True(check(v))
+		}
+		for _, v := range list[1] {
+			g.Desc("%s %s", format, v).False(check(v))
+		}
+	}
+}
+
+func TestAddFormat(t *testing.T) {
+	g := got.T(t)
+
+	type User struct {
+		Name  string `json:"name" format:"name"`
+		Email string `json:"email" format:"email"`
+	}
+
+	s := jschema.New("")
+	s.Define(User{})
+	ref := s.Ref(User{})
+
+	validate := func(s jschema.Schemas, data string) error {
+		v, err := s.Compile(ref)
+		g.E(err)
+		return v.Validate([]byte(data))
+	}
+
+	g.Nil(validate(s, `{"name":"", "email":"a@b.io"}`))
+	g.Eq(validate(s, `{"name":"", "email":"a"}`).Error(),
+		`jschema: invalid value: /email: Does not match format 'email'`)
+
+	_, err := s.WithStrictFormats().Compile(ref)
+	g.Eq(err.Error(), `jschema: unknown format "name"`)
+
+	s.AddFormat("name", func(s string) bool { return s != "" && strings.ToLower(s[:1]) != s[:1] })
+	s.AddFormat("email", func(s string) bool { return strings.HasSuffix(s, "@b.io") })
+
+	strict := s.WithStrictFormats()
+	g.Nil(validate(strict, `{"name":"Jo", "email":"x@b.io"}`))
+	g.Eq(validate(strict, `{"name":"jo", "email":"a@c.io"}`).Error(),
+		`jschema: invalid value: /name: Does not match format 'name'; /email: Does not match format 'email'`)
+
+	// The built-in formats are not affected.
+	g.True(jschema.Formats["email"]("a@c.io"))
+}
+
+func TestAddKeyword(t *testing.T) {
+	g := got.T(t)
+
+	type Range struct {
+		From  int            `json:"from" x-even:"true"`
+		To    int            `json:"to" x-even:"false" x-unit:"ms"`
+		Steps []int          `json:"steps" item-x-even:"true"`
+		Attrs map[string]int `json:"attrs" value-x-multipleOf:"3"`
+	}
+
+	s := jschema.New("")
+	s.Define(Range{})
+	ref := s.Ref(Range{})
+
+	scm := s.PeakSchema(Range{})
+	g.Eq(scm.Properties["from"].Extensions, map[string]jschema.JVal{"x-even": true})
+	g.Eq(scm.Properties["to"].Extensions, map[string]jschema.JVal{"x-even": false, "x-unit": "ms"})
+	g.Eq(scm.Properties["steps"].Items.Extensions, map[string]jschema.JVal{"x-even": true})
+	g.Eq(scm.Properties["attrs"].PatternProperties[""].Extensions, map[string]jschema.JVal{"x-multipleOf": 3.0})
+
+	validate := func() error {
+		v, err := s.Compile(ref)
+		g.E(err)
+		return v.Validate([]byte(`{"from":1,"to":2,"steps":[2,3],"attrs":{"a":4}}`))
+	}
+
+	// The keywords without checker are annotations only.
+	g.Nil(validate())
+
+	g.Eq(s.AddKeyword("even", nil).Error(), `jschema: the custom keyword "even" must start with "x-"`)
+
+	g.E(s.AddKeyword("x-even", func(value, data jschema.JVal) error {
+		if n, ok := data.(float64); ok && value == (int(n)%2 == 0) {
+			return nil
+		}
+		return fmt.Errorf("Must be even: %v", value)
+	}))
+	g.E(s.AddKeyword("x-multipleOf", func(value, data jschema.JVal) error {
+		if int(data.(float64))%int(value.(float64)) != 0 {
+			return fmt.Errorf("Must be a multiple of %v", value)
+		}
+		return nil
+	}))
+
+	err := validate()
+	g.Eq(err.Error(), `jschema: invalid value: /from: Must be even: true; /to: Must be even: false; `+
+		`/steps/1: Must be even: true; /attrs/a: Must be a multiple of 3`)
+	g.Eq(errStrings(err)[0], "x-even /from: Must be even: true")
+}
+
+func TestExtensionsJSON(t *testing.T) {
+	g := got.T(t)
+
+	scm := &jschema.Schema{
+		Type:                       jschema.TypeObject,
+		AdditionalPropertiesSchema: &jschema.Schema{Type: jschema.TypeString},
+		Extensions:                 map[string]jschema.JVal{"x-b": []int{1}, "x-a": "s"},
+	}
+
+	b, err := json.Marshal(scm)
+	g.E(err)
+	g.Eq(string(b), `{"type":"object","additionalProperties":{"type":"string"},"x-a":"s","x-b":[1]}`)
+
+	b, err = json.Marshal(&jschema.Schema{Extensions: map[string]jschema.JVal{"x-a": 1}})
+	g.E(err)
+	g.Eq(string(b), `{"x-a":1}`)
+
+	var out jschema.Schema
+	g.E(json.Unmarshal([]byte(`{"type":"object","properties":{"x-p":{"x-c":null}},"x-a":"s","x-b":[1]}`), &out))
+	g.Eq(out.Extensions, map[string]jschema.JVal{"x-a": "s", "x-b": []interface{}{1.0}})
+	g.Eq(out.Properties["x-p"].Extensions, map[string]jschema.JVal{"x-c": nil})
+
+	_, err = json.Marshal(&jschema.Schema{Extensions: map[string]jschema.JVal{"x-a": func() {}}})
+	g.Has(err.Error(), `jschema: invalid value of "x-a"`)
+}
@@ -1,6 +1,6 @@
 module github.com/ysmood/jschema
 
-go 1.20
+go 1.24
 
 require (
 	github.com/huandu/go-clone v1.6.0
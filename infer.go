@@ -0,0 +1,227 @@
+package jschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Infer generates the schema from the sample json documents with the same conventions as [Schemas.DefineT].
+// The properties present in all the samples are required, the values that have been null are nullable,
+// the arrays of mixed types have the anyOf items, and the object shapes that appear more than once
+// are moved into the $defs.
+func Infer(samples ...[]byte) (*Schema, error) {
+	root := &inferNode{}
+
+	for i, sample := range samples {
+		var v interface{}
+
+		d := json.NewDecoder(bytes.NewReader(sample))
+		d.UseNumber()
+
+		err := d.Decode(&v)
+		if err != nil {
+			return nil, fmt.Errorf("jschema: invalid sample %d: %w", i, err)
+		}
+
+		root.merge(v)
+	}
+
+	ctx := &inferContext{shapes: map[string]*inferShape{}}
+	scm := ctx.schema(root, "Root")
+
+	return ctx.extractDefs(scm), nil
+}
+
+// inferNode is the merged type information of the values at the same location of the samples.
+type inferNode struct {
+	null    bool
+	boolean bool
+	integer bool
+	number  bool
+	string  bool
+	array   bool
+
+	items  *inferNode
+	object *inferObject
+}
+
+type inferObject struct {
+	count int
+	keys  []string
+	seen  map[string]int
+	props map[string]*inferNode
+}
+
+func (n *inferNode) merge(v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		n.null = true
+
+	case bool:
+		n.boolean = true
+
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			n.number = true
+		} else {
+			n.integer = true
+		}
+
+	case string:
+		n.string = true
+
+	case []interface{}:
+		n.array = true
+		for _, el := range v {
+			if n.items == nil {
+				n.items = &inferNode{}
+			}
+			n.items.merge(el)
+		}
+
+	case map[string]interface{}:
+		if n.object == nil {
+			n.object = &inferObject{seen: map[string]int{}, props: map[string]*inferNode{}}
+		}
+
+		o := n.object
+		o.count++
+
+		for _, k := range sortedKeys(v) {
+			if _, has := o.props[k]; !has {
+				o.keys = append(o.keys, k)
+				o.props[k] = &inferNode{}
+			}
+			o.seen[k]++
+			o.props[k].merge(v[k])
+		}
+	}
+}
+
+type inferContext struct {
+	shapes map[string]*inferShape
+	order  []*inferShape
+}
+
+// inferShape is an object schema and all the places it appears.
+type inferShape struct {
+	name  string
+	sites []*Schema
+}
+
+// schema converts the node to schema, the name is the hint for the name of the object shapes.
+func (ctx *inferContext) schema(n *inferNode, name string) *Schema {
+	list := []*Schema{}
+
+	if n.boolean {
+		list = append(list, &Schema{Type: TypeBool})
+	}
+
+	if n.number {
+		list = append(list, &Schema{Type: TypeNumber})
+	} else if n.integer {
+		list = append(list, &Schema{Type: TypeInteger})
+	}
+
+	if n.string {
+		list = append(list, &Schema{Type: TypeString})
+	}
+
+	if n.array {
+		scm := &Schema{Type: TypeArray}
+		if n.items != nil {
+			scm.Items = ctx.schema(n.items, name+"Item")
+		}
+		list = append(list, scm)
+	}
+
+	if n.object != nil {
+		list = append(list, ctx.objectSchema(n.object, name))
+	}
+
+	switch {
+	case n.null && len(list) == 0:
+		return &Schema{Type: TypeNull}
+	case n.null:
+		return &Schema{AnyOf: append(list, &Schema{Type: TypeNull})}
+	case len(list) == 1:
+		return list[0]
+	}
+
+	return &Schema{AnyOf: list}
+}
+
+func (ctx *inferContext) objectSchema(o *inferObject, name string) *Schema {
+	scm := &Schema{
+		Type:                 TypeObject,
+		Properties:           Properties{},
+		AdditionalProperties: new(bool),
+	}
+
+	for _, k := range o.keys {
+		scm.Properties[k] = ctx.schema(o.props[k], ExportName(k))
+		if o.seen[k] == o.count {
+			scm.Required.Add(k)
+		}
+	}
+
+	if len(o.keys) == 0 {
+		return scm
+	}
+
+	key := toString(scm)
+
+	shape, has := ctx.shapes[key]
+	if !has {
+		shape = &inferShape{name: name}
+		ctx.shapes[key] = shape
+		ctx.order = append(ctx.order, shape)
+	}
+
+	shape.sites = append(shape.sites, scm)
+
+	return scm
+}
+
+// extractDefs moves the object shapes that appear more than once into the $defs of the root schema.
+func (ctx *inferContext) extractDefs(root *Schema) *Schema {
+	defs := Types{}
+
+	// The shapes are in post-order, so the nested shapes are replaced before their parents are copied.
+	for _, shape := range ctx.order {
+		if len(shape.sites) < 2 {
+			continue
+		}
+
+		id := shape.name
+		for i := 1; defs[id] != nil; i++ {
+			id = fmt.Sprintf("%s%d", shape.name, i)
+		}
+
+		def := *shape.sites[0]
+		def.Title = id
+		defs[id] = &def
+
+		for _, site := range shape.sites {
+			*site = Schema{Ref: &Ref{Defs: "#/$defs", Name: id, ID: id}}
+		}
+	}
+
+	if len(defs) > 0 {
+		root.Defs = defs
+	}
+
+	return root
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
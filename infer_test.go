@@ -0,0 +1,130 @@
+package jschema_test
+
+import (
+	"testing"
+
+	"github.com/xeipuuv/gojsonschema"
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+)
+
+func TestInfer(t *testing.T) {
+	g := got.T(t)
+
+	a := []byte(`{
+		"id": 1,
+		"name": "a",
+		"home": {"city": "x", "zip": "1"},
+		"work": {"city": "y", "zip": "2"},
+		"tags": ["a", 1, {"k": true}],
+		"score": 1.5,
+		"parent": null
+	}`)
+
+	b := []byte(`{
+		"id": 2,
+		"home": {"city": "z", "zip": "3"},
+		"work": {"city": "w", "zip": "4"},
+		"tags": [],
+		"score": 2,
+		"parent": {"id": 1}
+	}`)
+
+	scm, err := jschema.Infer(a, b)
+	g.E(err)
+
+	g.Eq(g.JSON(scm.String()), map[string]interface{}{
+		"$defs": map[string]interface{}{
+			"Home": map[string]interface{}{
+				"additionalProperties": false,
+				"properties": map[string]interface{}{
+					"city": map[string]interface{}{"type": "string"},
+					"zip":  map[string]interface{}{"type": "string"},
+				},
+				"required": []interface{}{"city", "zip"},
+				"title":    "Home",
+				"type":     "object",
+			},
+		},
+		"additionalProperties": false,
+		"properties": map[string]interface{}{
+			"home": map[string]interface{}{"$ref": "#/$defs/Home"},
+			"id":   map[string]interface{}{"type": "integer"},
+			"name": map[string]interface{}{"type": "string"},
+			"parent": map[string]interface{}{
+				"anyOf": []interface{}{
+					map[string]interface{}{
+						"additionalProperties": false,
+						"properties": map[string]interface{}{
+							"id": map[string]interface{}{"type": "integer"},
+						},
+						"required": []interface{}{"id"},
+						"type":     "object",
+					},
+					map[string]interface{}{"type": "null"},
+				},
+			},
+			"score": map[string]interface{}{"type": "number"},
+			"tags": map[string]interface{}{
+				"items": map[string]interface{}{
+					"anyOf": []interface{}{
+						map[string]interface{}{"type": "integer"},
+						map[string]interface{}{"type": "string"},
+						map[string]interface{}{
+							"additionalProperties": false,
+							"properties": map[string]interface{}{
+								"k": map[string]interface{}{"type": "boolean"},
+							},
+							"required": []interface{}{"k"},
+							"type":     "object",
+						},
+					},
+				},
+				"type": "array",
+			},
+			"work": map[string]interface{}{"$ref": "#/$defs/Home"},
+		},
+		"required": []interface{}{"home", "id", "parent", "score", "tags", "work"},
+		"type":     "object",
+	})
+
+	for _, sample := range [][]byte{a, b} {
+		res, err := gojsonschema.Validate(gojsonschema.NewGoLoader(scm), gojsonschema.NewBytesLoader(sample))
+		g.E(err)
+		g.Desc("%v", res.Errors()).True(res.Valid())
+	}
+
+	_, err = jschema.Infer([]byte(`{`))
+	g.Eq(err.Error(), "jschema: invalid sample 0: unexpected EOF")
+}
+
+func TestInferNested(t *testing.T) {
+	g := got.T(t)
+
+	scm, err := jschema.Infer([]byte(`[{"a": {"b": 1}}, {"a": {"b": 2}}, {"c": {"b": 3}}]`))
+	g.E(err)
+
+	s := jschema.New("")
+	g.Eq(g.JSON(s.ToStandAlone(scm).String()), map[string]interface{}{
+		"$defs": map[string]interface{}{
+			"A": map[string]interface{}{
+				"additionalProperties": false,
+				"properties": map[string]interface{}{
+					"b": map[string]interface{}{"type": "integer"},
+				},
+				"required": []interface{}{"b"},
+				"title":    "A",
+				"type":     "object",
+			},
+		},
+		"items": map[string]interface{}{
+			"additionalProperties": false,
+			"properties": map[string]interface{}{
+				"a": map[string]interface{}{"$ref": "#/$defs/A"},
+				"c": map[string]interface{}{"$ref": "#/$defs/A"},
+			},
+			"type": "object",
+		},
+		"type": "array",
+	})
+}
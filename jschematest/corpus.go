@@ -0,0 +1,332 @@
+package jschematest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ysmood/jschema"
+)
+
+// Seed is a json document of the fuzz corpus.
+type Seed struct {
+	JSON []byte
+
+	// Keyword is the schema keyword that the JSON violates, such as "required" and "maximum".
+	// It's empty if the JSON is valid.
+	Keyword string
+
+	// Path is the json pointer of the value that violates the Keyword, such as "/items/0/name".
+	Path string
+}
+
+// Valid returns true if the seed is valid for the schema.
+func (s Seed) Valid() bool {
+	return s.Keyword == ""
+}
+
+func (s Seed) String() string {
+	if s.Valid() {
+		return fmt.Sprintf("valid: %s", s.JSON)
+	}
+	return fmt.Sprintf("%s at %q: %s", s.Keyword, s.Path, s.JSON)
+}
+
+// SeedCorpus adds the seeds from [Seeds] to the fuzz corpus of f, such as:
+//
+//	func FuzzDecode(f *testing.F) {
+//		seeds := jschematest.SeedCorpus(f, s, Node{}, 10)
+//
+//		f.Fuzz(func(t *testing.T, b []byte) {
+//			var n Node
+//			_ = json.Unmarshal(b, &n)
+//		})
+//	}
+//
+// It returns the seeds, so that a finding of a seed can be mapped back to the keyword it violates.
+func SeedCorpus(f *testing.F, s jschema.Schemas, v interface{}, n int) []Seed {
+	f.Helper()
+
+	seeds, err := Seeds(s, v, n)
+	if err != nil {
+		f.Fatalf("jschematest: failed to generate the seeds: %v", err)
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed.JSON)
+	}
+
+	return seeds
+}
+
+// Seeds returns n valid samples of the schema of v's type, and for each sample the minimally invalid
+// documents that violate one constraint at a time, such as the boundary values, missing required properties,
+// unknown properties and wrong types. The valid boundary values are included too.
+// The type of v will be defined in s if it's not yet. The seeds are deterministic and verified by
+// [jschema.Schemas.ValidateJSON], the ones that don't behave as expected are dropped.
+func Seeds(s jschema.Schemas, v interface{}, n int) ([]Seed, error) {
+	s.Define(v)
+	ref := s.Ref(v)
+
+	list := []Seed{}
+	seen := map[string]bool{}
+
+	add := func(seed Seed) {
+		if seen[string(seed.JSON)] {
+			return
+		}
+
+		// The mutations may be valid by accident, such as a wrong type that is accepted by an anyOf.
+		if (s.ValidateJSON(ref, seed.JSON) == nil) != seed.Valid() {
+			return
+		}
+
+		seen[string(seed.JSON)] = true
+		list = append(list, seed)
+	}
+
+	for i := 0; i < n; i++ {
+		b, err := s.Sample(ref, jschema.SampleOptions{Seed: int64(i)})
+		if err != nil {
+			return nil, err
+		}
+
+		add(Seed{JSON: b})
+
+		var doc interface{}
+		_ = json.Unmarshal(b, &doc)
+
+		m := &mutator{s: s}
+		m.walk(&jschema.Schema{Ref: &ref}, doc, []string{})
+
+		for _, mu := range m.list {
+			add(Seed{JSON: mu.apply(doc), Keyword: mu.keyword, Path: pointer(mu.path)})
+		}
+	}
+
+	return list, nil
+}
+
+// mutation replaces or removes the value at the path of a document.
+type mutation struct {
+	path    []string
+	keyword string
+	value   interface{}
+	remove  bool
+}
+
+// apply returns the json of the mutated copy of doc.
+func (mu mutation) apply(doc interface{}) []byte {
+	b, _ := json.Marshal(doc) //nolint: errchkjson
+	var cp interface{}
+	_ = json.Unmarshal(b, &cp)
+
+	if len(mu.path) == 0 {
+		cp = mu.value
+	} else {
+		parent := cp
+		for _, key := range mu.path[:len(mu.path)-1] {
+			parent = child(parent, key)
+		}
+
+		last := mu.path[len(mu.path)-1]
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			if mu.remove {
+				delete(p, last)
+			} else {
+				p[last] = mu.value
+			}
+		case []interface{}:
+			i, _ := strconv.Atoi(last)
+			p[i] = mu.value
+		}
+	}
+
+	b, _ = json.Marshal(cp) //nolint: errchkjson
+	return b
+}
+
+func child(v interface{}, key string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return v[key]
+	case []interface{}:
+		i, _ := strconv.Atoi(key)
+		return v[i]
+	}
+	return nil
+}
+
+// pointer returns the json pointer of the path.
+func pointer(path []string) string {
+	b := &strings.Builder{}
+	for _, key := range path {
+		b.WriteString("/")
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(key))
+	}
+	return b.String()
+}
+
+type mutator struct {
+	s    jschema.Schemas
+	list []mutation
+}
+
+func (m *mutator) add(path []string, keyword string, value interface{}) {
+	m.list = append(m.list, mutation{path: append([]string{}, path...), keyword: keyword, value: value})
+}
+
+// walk collects the mutations of the value v of the schema scm.
+func (m *mutator) walk(scm *jschema.Schema, v interface{}, path []string) { //nolint: cyclop
+	if scm.Ref != nil {
+		def := m.s.Get(scm.Ref.ID)
+		if def == nil {
+			return
+		}
+		scm = def
+	}
+
+	if v == nil {
+		return
+	}
+
+	if len(scm.AnyOf) > 0 {
+		// Only the nullable anyOf is followed, the value of it must be the non-null one.
+		if len(scm.AnyOf) == 2 && scm.AnyOf[1].Type == jschema.TypeNull {
+			m.walk(scm.AnyOf[0], v, path)
+		}
+		return
+	}
+
+	if len(scm.Enum) > 0 {
+		m.add(path, "enum", "jschematest-not-in-enum")
+		return
+	}
+
+	if scm.Type != "" {
+		m.add(path, "type", wrongType(scm.Type))
+	}
+
+	//nolint: exhaustive
+	switch scm.Type {
+	case jschema.TypeInteger, jschema.TypeNumber:
+		if scm.Min != nil {
+			m.add(path, "", *scm.Min)
+			m.add(path, "minimum", *scm.Min-1)
+		}
+		if scm.Max != nil {
+			m.add(path, "", *scm.Max)
+			m.add(path, "maximum", *scm.Max+1)
+		}
+		if f := math.Floor(num(scm.Min, scm.Max)) + 0.5; scm.Type == jschema.TypeInteger && (scm.Max == nil || f < *scm.Max) {
+			m.add(path, "type", f)
+		}
+
+	case jschema.TypeString:
+		m.strings(scm, path)
+
+	case jschema.TypeArray:
+		list, _ := v.([]interface{})
+		m.arrays(scm, list, path)
+
+	case jschema.TypeObject:
+		obj, _ := v.(map[string]interface{})
+		m.objects(scm, obj, path)
+	}
+}
+
+func (m *mutator) strings(scm *jschema.Schema, path []string) {
+	if scm.MinLen != nil && *scm.MinLen > 0 {
+		m.add(path, "", strings.Repeat("a", int(*scm.MinLen)))
+		m.add(path, "minLength", strings.Repeat("a", int(*scm.MinLen)-1))
+	}
+	if scm.MaxLen != nil {
+		m.add(path, "", strings.Repeat("a", int(*scm.MaxLen)))
+		m.add(path, "maxLength", strings.Repeat("a", int(*scm.MaxLen)+1))
+	}
+	if scm.Pattern != "" {
+		re, err := regexp.Compile(scm.Pattern)
+		if err != nil {
+			return
+		}
+		for _, str := range []string{"", "-", "0", "a", " "} {
+			if !re.MatchString(str) {
+				m.add(path, "pattern", str)
+				break
+			}
+		}
+	}
+}
+
+func (m *mutator) arrays(scm *jschema.Schema, list []interface{}, path []string) {
+	for i, item := range list {
+		if i < len(scm.PrefixItems) {
+			m.walk(scm.PrefixItems[i], item, append(path, strconv.Itoa(i)))
+		} else if scm.Items != nil {
+			m.walk(scm.Items, item, append(path, strconv.Itoa(i)))
+		}
+	}
+
+	// The sample may have fewer items than the minItems, such as the one from a default value.
+	if scm.MinItems != nil && *scm.MinItems > 0 && len(list) > 0 {
+		n := *scm.MinItems - 1
+		if n > len(list) {
+			n = len(list)
+		}
+		m.add(path, "minItems", list[:n])
+	}
+	if scm.MaxItems != nil && len(list) > 0 {
+		more := append([]interface{}{}, list...)
+		for len(more) <= *scm.MaxItems {
+			more = append(more, list[0])
+		}
+		m.add(path, "maxItems", more)
+	}
+}
+
+func (m *mutator) objects(scm *jschema.Schema, obj map[string]interface{}, path []string) {
+	names := []string{}
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if p, has := scm.Properties[name]; has {
+			m.walk(p, obj[name], append(path, name))
+		}
+
+		if scm.Required.Has(name) {
+			m.list = append(m.list, mutation{path: append(append([]string{}, path...), name), keyword: "required", remove: true})
+		}
+	}
+
+	if scm.AdditionalProperties != nil && !*scm.AdditionalProperties && scm.AdditionalPropertiesSchema == nil &&
+		len(scm.PatternProperties) == 0 {
+		m.add(append(path, "jschematest-unknown"), "additionalProperties", true)
+	}
+}
+
+// wrongType returns a value that is not of the type t.
+func wrongType(t jschema.SchemaType) interface{} {
+	if t == jschema.TypeString {
+		return 0.0
+	}
+	return "jschematest-wrong-type"
+}
+
+func num(minimum, maximum *float64) float64 {
+	switch {
+	case minimum != nil:
+		return *minimum
+	case maximum != nil:
+		return *maximum - 1
+	}
+	return 0
+}
@@ -0,0 +1,85 @@
+package jschematest_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+	"github.com/ysmood/jschema/jschematest"
+)
+
+type Order struct {
+	ID    string   `json:"id" minLen:"2" maxLen:"4"`
+	Count int      `json:"count" min:"1" max:"9"`
+	Tags  []string `json:"tags" minItems:"1" maxItems:"2"`
+	Note  *string  `json:"note"`
+	Code  string   `json:"code" pattern:"^[a-z]+$"`
+}
+
+func TestSeeds(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+
+	seeds, err := jschematest.Seeds(s, Order{}, 3)
+	g.E(err)
+
+	keywords := ""
+	for _, seed := range seeds {
+		err := s.ValidateJSON(s.Ref(Order{}), seed.JSON)
+		g.Eq(err == nil, seed.Valid())
+		keywords += seed.Keyword + " " + seed.Path + "\n"
+	}
+
+	g.True(seeds[0].Valid())
+	g.Has(keywords, "\ntype \n")
+	g.Has(keywords, "required /id\n")
+	g.Has(keywords, "additionalProperties /jschematest-unknown\n")
+	g.Has(keywords, "minLength /id\n")
+	g.Has(keywords, "maxLength /id\n")
+	g.Has(keywords, "minimum /count\n")
+	g.Has(keywords, "maximum /count\n")
+	g.Has(keywords, "type /count\n")
+	g.Has(keywords, "minItems /tags\n")
+	g.Has(keywords, "maxItems /tags\n")
+	g.Has(keywords, "type /tags/0\n")
+	g.Has(keywords, "pattern /code\n")
+
+	again, _ := jschematest.Seeds(s, Order{}, 3)
+	g.Eq(again, seeds)
+
+	g.Eq(jschematest.Seed{JSON: []byte(`{}`), Keyword: "required", Path: "/id"}.String(), `required at "/id": {}`)
+	g.Eq(jschematest.Seed{JSON: []byte(`1`)}.String(), `valid: 1`)
+}
+
+func TestSeedsShortDefault(t *testing.T) {
+	g := got.T(t)
+
+	type Short struct {
+		Tags []string `json:"tags" minItems:"3" default:"[\"a\"]"`
+	}
+
+	s := jschema.New("")
+
+	seeds, err := jschematest.Seeds(s, Short{}, 3)
+	g.E(err)
+	g.Gt(len(seeds), 0)
+}
+
+func FuzzSeedCorpus(f *testing.F) {
+	s := jschema.New("")
+	jschematest.SeedCorpus(f, s, Order{}, 3)
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var o Order
+		if json.Unmarshal(b, &o) != nil {
+			return
+		}
+
+		// The value decoded from a valid json encodes to a valid json.
+		if s.ValidateJSON(s.Ref(Order{}), b) == nil {
+			jschematest.AssertValid(t, s, o)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+// Package jschematest provides the helpers to test the schemas in the unit tests.
+package jschematest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ysmood/got/lib/diff"
+	"github.com/ysmood/jschema"
+)
+
+var update = flag.Bool("jschematest.update", false, "rewrite the golden files of jschematest")
+
+// Golden asserts the json of s is the same as the content of the file, such as:
+//
+//	jschematest.Golden(t, s, "testdata/schemas.json")
+//
+// Run "go test -jschematest.update" to create or rewrite the file with the current json of s.
+// Each changed definition is reported with its own diff.
+func Golden(t testing.TB, s jschema.Schemas, file string) {
+	t.Helper()
+
+	if *update {
+		err := os.MkdirAll(filepath.Dir(file), 0o755)
+		if err == nil {
+			err = os.WriteFile(file, []byte(s.String()+"\n"), 0o644) //nolint: gosec
+		}
+		if err != nil {
+			t.Errorf("jschematest: failed to update the golden file: %v", err)
+		}
+		return
+	}
+
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Errorf("jschematest: golden file %s doesn't exist, run the test with -jschematest.update to create it", file)
+		return
+	} else if err != nil {
+		t.Errorf("jschematest: failed to read the golden file: %v", err)
+		return
+	}
+
+	var golden map[string]json.RawMessage
+	err = json.Unmarshal(b, &golden)
+	if err != nil {
+		t.Errorf("jschematest: invalid golden file %s: %v", file, err)
+		return
+	}
+
+	types := s.JSON()
+
+	for _, id := range ids(golden, types) {
+		expected, has := golden[id]
+		if !has {
+			t.Errorf("jschematest: definition %q is not in the golden file %s", id, file)
+			continue
+		}
+
+		scm, has := types[id]
+		if !has {
+			t.Errorf("jschematest: definition %q of the golden file %s is not defined", id, file)
+			continue
+		}
+
+		x, y := indent(expected), indent(scm)
+		if x != y {
+			t.Errorf("jschematest: definition %q differs from the golden file %s:\n%s", id, file, lineDiff(x, y))
+		}
+	}
+}
+
+// AssertValid asserts each of the values marshals to the json that validates against the schema of its type in s.
+// The types of the values will be defined in s if they are not yet.
+func AssertValid(t testing.TB, s jschema.Schemas, values ...interface{}) {
+	t.Helper()
+
+	for _, v := range values {
+		err := s.Validate(v)
+		if err != nil {
+			b, _ := json.Marshal(v) //nolint: errchkjson
+			t.Errorf("jschematest: %s of %T: %v", b, v, err)
+		}
+	}
+}
+
+func ids(golden map[string]json.RawMessage, types map[string]*jschema.Schema) []string {
+	list := []string{}
+	for id := range golden {
+		list = append(list, id)
+	}
+	for id := range types {
+		if _, has := golden[id]; !has {
+			list = append(list, id)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// indent returns the indented json of v with the sorted keys, so that the equivalent values have the same output.
+func indent(v interface{}) string {
+	b, _ := json.Marshal(v) //nolint: errchkjson
+
+	var x interface{}
+	_ = json.Unmarshal(b, &x)
+
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(x)
+	return strings.TrimSpace(buf.String())
+}
+
+// lineDiff returns the plain text diff of the expected x and the actual y.
+func lineDiff(x, y string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return diff.Format(diff.Tokenize(ctx, x, y), diff.ThemeNone)
+}
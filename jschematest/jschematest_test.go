@@ -0,0 +1,93 @@
+package jschematest_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+	"github.com/ysmood/jschema/jschematest"
+)
+
+type mockT struct {
+	testing.TB
+	errs []string
+}
+
+func (t *mockT) Helper() {}
+
+func (t *mockT) Errorf(format string, args ...interface{}) {
+	t.errs = append(t.errs, fmt.Sprintf(format, args...))
+}
+
+type A struct {
+	ID   int    `json:"id"`
+	Name string `json:"name" maxLen:"3"`
+}
+
+type B struct {
+	A A `json:"a"`
+}
+
+func TestGolden(t *testing.T) {
+	g := got.T(t)
+
+	file := filepath.Join(t.TempDir(), "testdata", "schemas.json")
+
+	s := jschema.New("")
+	s.Define(B{})
+
+	m := &mockT{TB: t}
+	jschematest.Golden(m, s, file)
+	g.Len(m.errs, 1)
+	g.Has(m.errs[0], "run the test with -jschematest.update to create it")
+
+	g.E(flag.Set("jschematest.update", "true"))
+	jschematest.Golden(m, s, file)
+	g.E(flag.Set("jschematest.update", "false"))
+
+	m = &mockT{TB: t}
+	jschematest.Golden(m, s, file)
+	g.Len(m.errs, 0)
+
+	type C struct{}
+
+	s.Define(C{})
+	s.Get("A").Properties["name"].MaxLen = jschema.Ptr(5.0)
+	s.Remove(B{})
+
+	m = &mockT{TB: t}
+	jschematest.Golden(m, s, file)
+	g.Eq(m.errs[0], `jschematest: definition "A" differs from the golden file `+file+`:
+@@ diff chunk @@
+08 08       "name": {
+09    -       "maxLength": 3,
+   09 +       "maxLength": 5,
+10 10         "type": "string"
+
+`)
+	g.Eq(m.errs[1], `jschematest: definition "B" of the golden file `+file+` is not defined`)
+	g.Eq(m.errs[2], `jschematest: definition "C" is not in the golden file `+file)
+
+	g.E(os.WriteFile(file, []byte("{"), 0o600))
+	m = &mockT{TB: t}
+	jschematest.Golden(m, s, file)
+	g.Has(m.errs[0], "jschematest: invalid golden file")
+}
+
+func TestAssertValid(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+
+	m := &mockT{TB: t}
+	jschematest.AssertValid(m, s, A{ID: 1, Name: "abc"}, B{A: A{Name: "abcd"}})
+
+	g.Eq(m.errs, []string{
+		`jschematest: {"a":{"id":0,"name":"abcd"}} of jschematest_test.B: ` +
+			`jschema: invalid value: /a/name: String length must be less than or equal to 3`,
+	})
+}
@@ -0,0 +1,227 @@
+package jschematest
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+
+	"github.com/ysmood/jschema"
+)
+
+// maxDepth is the max depth of the generated values, the deeper collections are empty and pointers are nil.
+const maxDepth = 4
+
+var (
+	tJSONUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	tTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// random generates the values from a clone of the caller's schema list, because the field schemas are defined on the fly.
+type random struct {
+	r *rand.Rand
+	s jschema.Schemas
+
+	// err is the first error of generating the samples, such as the ones of the types that have custom decoders.
+	err error
+}
+
+// value returns a random value of t, scm is the schema of the value to respect the keywords such as the maximum, it can be nil.
+// The types that have custom decoders are decoded from the [jschema.Schemas.Sample] of their schemas.
+// The slices and maps are never nil, because their schemas don't accept null.
+func (g *random) value(t reflect.Type, scm *jschema.Schema, depth int) reflect.Value { //nolint: cyclop
+	v := reflect.New(t).Elem()
+	scm = g.resolve(scm)
+
+	if t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface && (len(scm.Enum) > 0 ||
+		reflect.PtrTo(t).Implements(tJSONUnmarshaler) || reflect.PtrTo(t).Implements(tTextUnmarshaler)) {
+		var b []byte
+		var err error
+		if len(scm.Enum) > 0 {
+			b, err = json.Marshal(scm.Enum[g.r.Intn(len(scm.Enum))])
+		} else if r := g.s.DefineT(t); r.Ref != nil {
+			b, err = g.s.Sample(*r.Ref, jschema.SampleOptions{Seed: g.r.Int63()})
+		}
+		if err == nil && b != nil {
+			err = json.Unmarshal(b, v.Addr().Interface())
+		}
+		if err != nil && g.err == nil {
+			g.err = fmt.Errorf("failed to decode %s from %s: %w", t, b, err)
+		}
+		return v
+	}
+
+	//nolint: exhaustive
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(g.r.Intn(2) == 1)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(g.num(scm.Min, scm.Max)))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		minimum := 0.0
+		if scm.Min != nil {
+			minimum = *scm.Min
+		}
+		v.SetUint(uint64(g.num(&minimum, scm.Max)))
+
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(int(g.num(scm.Min, scm.Max)*10)) / 10)
+
+	case reflect.String:
+		v.SetString(g.constrainedStr(scm))
+
+	case reflect.Ptr:
+		if depth < maxDepth && g.r.Intn(4) != 0 {
+			v.Set(g.value(t.Elem(), scm, depth+1).Addr())
+		}
+
+	case reflect.Slice:
+		n := 0
+		if depth < maxDepth {
+			n = g.r.Intn(4)
+		}
+		v.Set(reflect.MakeSlice(t, n, n))
+		for i := 0; i < n; i++ {
+			v.Index(i).Set(g.value(t.Elem(), scm.Items, depth+1))
+		}
+
+	case reflect.Array:
+		for i := 0; i < t.Len(); i++ {
+			v.Index(i).Set(g.value(t.Elem(), scm.Items, depth+1))
+		}
+
+	case reflect.Map:
+		v.Set(reflect.MakeMap(t))
+		if depth < maxDepth {
+			keys, values := scm.PropertyNames, scm.AdditionalPropertiesSchema
+			if values == nil && len(scm.PatternProperties) == 1 {
+				// The key pattern of the map is the only pattern property.
+				for p, ps := range scm.PatternProperties {
+					values = ps
+					if p != "" {
+						keys = &jschema.Schema{Pattern: p}
+						if scm.PropertyNames != nil {
+							keys = scm.PropertyNames.Clone()
+							keys.Pattern = p
+						}
+					}
+				}
+			}
+			for i := g.r.Intn(3); i > 0; i-- {
+				v.SetMapIndex(g.value(t.Key(), keys, depth+1), g.value(t.Elem(), values, depth+1))
+			}
+		}
+
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			// The field has one property unless it's inlined.
+			var p *jschema.Schema
+			if props := g.s.DefineFieldT(f).Properties; len(props) == 1 {
+				for _, prop := range props {
+					p = prop
+				}
+			}
+
+			v.Field(i).Set(g.value(f.Type, p, depth+1))
+		}
+	}
+
+	return v
+}
+
+// resolve follows the $ref and the nullable anyOf of scm, it returns an empty schema if scm is nil.
+func (g *random) resolve(scm *jschema.Schema) *jschema.Schema {
+	if scm == nil {
+		return &jschema.Schema{}
+	}
+
+	if scm.Ref != nil {
+		if def := g.s.Get(scm.Ref.ID); def != nil {
+			return g.resolve(def)
+		}
+	}
+
+	if len(scm.AnyOf) == 2 && scm.AnyOf[1].Type == jschema.TypeNull {
+		return g.resolve(scm.AnyOf[0])
+	}
+
+	return scm
+}
+
+func (g *random) num(minimum, maximum *float64) float64 {
+	low, high := -100.0, 100.0
+	if minimum != nil {
+		low = *minimum
+		if maximum == nil {
+			high = low + 200
+		}
+	}
+	if maximum != nil {
+		high = *maximum
+		if minimum == nil {
+			low = high - 200
+		}
+	}
+
+	if high-low < 1 {
+		return low
+	}
+
+	return low + float64(g.r.Int63n(int64(high-low)+1))
+}
+
+// constrainedStr returns a random string for the schema, the pattern and format are generated by
+// [jschema.Schemas.SampleSchema].
+func (g *random) constrainedStr(scm *jschema.Schema) string {
+	if scm.Pattern == "" && scm.Format == "" {
+		return g.str(intOf(scm.MinLen, 0), intOf(scm.MaxLen, 8))
+	}
+
+	str := &jschema.Schema{
+		Type:    jschema.TypeString,
+		Pattern: scm.Pattern,
+		Format:  scm.Format,
+		MinLen:  scm.MinLen,
+		MaxLen:  scm.MaxLen,
+	}
+
+	var v string
+	b, err := g.s.SampleSchema(str, jschema.SampleOptions{Seed: g.r.Int63()})
+	if err == nil {
+		err = json.Unmarshal(b, &v)
+	}
+	if err != nil && g.err == nil {
+		g.err = fmt.Errorf("failed to generate the string of the pattern %q and format %q: %w", scm.Pattern, scm.Format, err)
+	}
+
+	return v
+}
+
+func (g *random) str(minLen, maxLen int) string {
+	if maxLen < minLen {
+		maxLen = minLen
+	}
+
+	const letters = "abcdefghijklmnopqrstuvwxyz"
+
+	b := make([]byte, minLen+g.r.Intn(maxLen-minLen+1))
+	for i := range b {
+		b[i] = letters[g.r.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+func intOf(f *float64, def int) int {
+	if f == nil {
+		return def
+	}
+	return int(*f)
+}
@@ -0,0 +1,69 @@
+package jschematest
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/ysmood/jschema"
+)
+
+// RoundTrip checks the consistency between each go type defined in s and its schema, such as:
+//
+//	s := jschema.New("")
+//	s.Define(Node{})
+//	jschematest.RoundTrip(t, s, 100, 0)
+//
+// For each type it generates n random go values and asserts the json of them validate against the schema,
+// then generates n samples of the schema by [jschema.Schemas.Sample] and asserts they can be unmarshaled into the type.
+// It catches the drift between the schemas and the encodings, such as the ones from hijacks and custom marshalers.
+// The seed makes the generated values deterministic, s is never modified. The definitions without go types, such as the ones
+// added by [jschema.Schemas.Load], are skipped.
+func RoundTrip(t testing.TB, s jschema.Schemas, n int, seed int64) {
+	t.Helper()
+
+	g := &random{r: rand.New(rand.NewSource(seed)), s: s.Clone()} //nolint: gosec
+
+	for _, ref := range s.Refs() {
+		typ := s.GoType(ref.ID)
+		if typ == nil {
+			continue
+		}
+
+		for i := 0; i < n; i++ {
+			v := g.value(typ, nil, 0).Interface()
+			if g.err != nil {
+				t.Errorf("jschematest: failed to generate %s: %v", ref, g.err)
+				g.err = nil
+				break
+			}
+
+			b, err := json.Marshal(v)
+			if err != nil {
+				t.Errorf("jschematest: failed to marshal %s: %v", ref, err)
+				break
+			}
+
+			err = s.ValidateJSON(ref, b)
+			if err != nil {
+				t.Errorf("jschematest: the json of %s doesn't match its schema: %s: %v", ref, b, err)
+				break
+			}
+		}
+
+		for i := 0; i < n; i++ {
+			b, err := s.Sample(ref, jschema.SampleOptions{Seed: g.r.Int63()})
+			if err != nil {
+				t.Errorf("jschematest: failed to sample %s: %v", ref, err)
+				break
+			}
+
+			err = json.Unmarshal(b, reflect.New(typ).Interface())
+			if err != nil {
+				t.Errorf("jschematest: the schema-valid json can't be unmarshaled into %s: %s: %v", ref, b, err)
+				break
+			}
+		}
+	}
+}
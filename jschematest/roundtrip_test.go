@@ -0,0 +1,117 @@
+package jschematest_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+	"github.com/ysmood/jschema/jschematest"
+	"github.com/ysmood/jschema/lib/test"
+)
+
+type Node struct {
+	ID       int               `json:"id" min:"1" max:"10"`
+	Name     string            `json:"name,omitempty" minLen:"1" maxLen:"3"`
+	Enum     test.Enum         `json:"enum"`
+	Time     time.Time         `json:"time"`
+	Labels   map[string]string `json:"labels"`
+	Index    map[int]bool      `json:"index"`
+	Point    [2]float64        `json:"point"`
+	Children []*Node           `json:"children"`
+	Any      interface{}       `json:"any"`
+}
+
+type Blob struct {
+	Data []byte `json:"data"`
+}
+
+type Level struct {
+	Value uint8 `json:"value" min:"200" max:"1000"`
+}
+
+type Broken struct {
+	V int `json:"v"`
+}
+
+func (Broken) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("broken")
+}
+
+type Holder struct {
+	Blob Blob `json:"blob"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+	s.Hijack(time.Time{}, func(scm *jschema.Schema) {
+		scm.Type = jschema.TypeString
+		scm.Format = "date-time"
+		scm.AdditionalProperties = nil
+	})
+	s.Define(Node{})
+
+	m := &mockT{TB: t}
+	jschematest.RoundTrip(m, s, 50, 0)
+	g.Eq(m.errs, []string(nil))
+
+	s.Define(Blob{})
+	s.Define(Level{})
+
+	m = &mockT{TB: t}
+	jschematest.RoundTrip(m, s, 50, 0)
+	g.Len(m.errs, 3)
+	g.True(strings.HasPrefix(m.errs[0], "jschematest: the json of github.com/ysmood/jschema/jschematest_test.Blob doesn't match its schema: "))
+	g.True(strings.HasPrefix(m.errs[1], "jschematest: the json of github.com/ysmood/jschema/jschematest_test.Level doesn't match its schema: "))
+	g.True(strings.HasPrefix(m.errs[2], "jschematest: the schema-valid json can't be unmarshaled into github.com/ysmood/jschema/jschematest_test.Level: "))
+}
+
+type Contact struct {
+	Email string            `json:"email" format:"email"`
+	Code  string            `json:"code" pattern:"^[A-Z]{2}-[0-9]{3}$"`
+	Tags  map[string]string `json:"tags" key-pattern:"^t-[a-z]+$" value-format:"uuid"`
+}
+
+func TestRoundTripStringConstraints(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+	s.Define(Contact{})
+
+	m := &mockT{TB: t}
+	jschematest.RoundTrip(m, s, 50, 0)
+	g.Eq(m.errs, []string(nil))
+}
+
+func TestRoundTripErr(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+	s.Define(Broken{})
+
+	m := &mockT{TB: t}
+	jschematest.RoundTrip(m, s, 10, 0)
+	g.Len(m.errs, 1)
+	g.True(strings.HasPrefix(m.errs[0], "jschematest: failed to marshal github.com/ysmood/jschema/jschematest_test.Broken: "))
+}
+
+func TestRoundTripReadOnly(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+	s.Define(Holder{})
+	s.Remove(Blob{})
+
+	refs := s.Refs()
+	str := s.String()
+
+	m := &mockT{TB: t}
+	jschematest.RoundTrip(m, s, 10, 0)
+
+	g.Eq(s.Refs(), refs)
+	g.Eq(s.String(), str)
+}
@@ -1,8 +1,10 @@
 package jschema
 
 import (
+	"bytes"
 	"encoding/json"
 	"reflect"
+	"strings"
 )
 
 // JSON returns a JSON representation of the schemas.
@@ -34,15 +36,98 @@ func (s *Schema) String() string {
 	return string(b)
 }
 
+// UnmarshalJSON decodes the "additionalProperties" that is a schema into the AdditionalPropertiesSchema,
+// and the keywords that start with the [KeywordPrefix] into the Extensions.
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	type plain Schema
+
+	raw := struct {
+		*plain
+		AdditionalProperties json.RawMessage `json:"additionalProperties"`
+	}{plain: (*plain)(s)}
+
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Contains(b, []byte(`"`+KeywordPrefix)) {
+		err = s.unmarshalExtensions(b)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(raw.AdditionalProperties) == 0 {
+		return nil
+	}
+
+	if raw.AdditionalProperties[0] == '{' {
+		s.AdditionalPropertiesSchema = &Schema{}
+		return json.Unmarshal(raw.AdditionalProperties, s.AdditionalPropertiesSchema)
+	}
+
+	return json.Unmarshal(raw.AdditionalProperties, &s.AdditionalProperties)
+}
+
+func (s *Schema) unmarshalExtensions(b []byte) error {
+	var keywords map[string]JVal
+
+	err := json.Unmarshal(b, &keywords)
+	if err != nil {
+		return err
+	}
+
+	for name, v := range keywords {
+		if strings.HasPrefix(name, KeywordPrefix) {
+			if s.Extensions == nil {
+				s.Extensions = map[string]JVal{}
+			}
+			s.Extensions[name] = v
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalJSON decodes the "$ref" path, such as "#/$defs/Node".
+// The Name and ID are the last segment of the path, the Package and Hash are empty.
+func (r *Ref) UnmarshalJSON(b []byte) error {
+	var p string
+
+	err := json.Unmarshal(b, &p)
+	if err != nil {
+		return err
+	}
+
+	i := strings.LastIndex(p, "/")
+	*r = Ref{Defs: p[:i+1], Name: p[i+1:], ID: p[i+1:]}
+	r.Defs = strings.TrimSuffix(r.Defs, "/")
+
+	return nil
+}
+
 type Tag struct {
 	Name      string
 	Ignore    bool
 	Omitempty bool
+	Omitzero  bool
 	String    bool
+
+	// Inline means the field should be flattened into its parent, such as `yaml:",inline"`.
+	Inline bool
 }
 
+// ParseJSONTag is a shortcut for [ParseTag] with the "json" key.
 func ParseJSONTag(st reflect.StructTag) *Tag {
-	v := st.Get("json")
+	return ParseTag(st, NameTagJSON)
+}
+
+// ParseTag parses the struct tag of the key, such as "json", "yaml", "toml", "mapstructure", "form".
+// The options are interpreted with the semantics of the format of the key,
+// unknown keys are interpreted as "json".
+func ParseTag(st reflect.StructTag, key string) *Tag {
+	v := st.Get(key)
 
 	if v == "" {
 		return nil
@@ -55,10 +140,20 @@ func ParseJSONTag(st reflect.StructTag) *Tag {
 	}
 
 	name, t := parseTag(v)
+	f := getNameTagFormat(key)
 
-	return &Tag{
+	tag := &Tag{
 		Name:      name,
 		Omitempty: t.Contains("omitempty"),
-		String:    t.Contains("string"),
+		Omitzero:  t.Contains("omitzero"),
+		String:    f.stringOption != "" && t.Contains(f.stringOption),
 	}
+
+	for _, o := range f.inlineOptions {
+		if t.Contains(o) {
+			tag.Inline = true
+		}
+	}
+
+	return tag
 }
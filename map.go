@@ -0,0 +1,81 @@
+package jschema
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	patternInteger  = "^-?[0-9]+$"
+	patternUnsigned = "^[0-9]+$"
+)
+
+var tTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// TextKeyFormats is the format of the map keys for the types that implement [encoding.TextMarshaler].
+var TextKeyFormats = map[reflect.Type]string{
+	reflect.TypeOf(time.Time{}): "date-time",
+}
+
+// defineMapKey returns the "propertyNames" schema for the map key type k,
+// it follows the rules of how [json.Marshal] encodes the map keys.
+// It panics if the key type is not supported by [json.Marshal], such as float64, because the map can't be encoded.
+func (s Schemas) defineMapKey(k reflect.Type) *Schema {
+	enum := mapKeyEnum(k)
+
+	switch {
+	case k.Kind() == reflect.String, k.Implements(tTextMarshaler):
+		if enum != nil {
+			return &Schema{Enum: enum}
+		}
+
+		if format, has := TextKeyFormats[k]; has {
+			return &Schema{Format: format}
+		}
+
+		return nil
+	}
+
+	//nolint: exhaustive
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Pattern: patternInteger}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Pattern: patternUnsigned}
+	}
+
+	panic(fmt.Sprintf("jschema: unsupported map key type %s", k))
+}
+
+// mapKeyEnum returns the string values of the enum type k.
+// The raw values of [Enum] that are not json strings are kept as their json text, such as 1 to "1",
+// because the json object keys are always strings.
+func mapKeyEnum(k reflect.Type) []JVal {
+	var list []JVal
+
+	switch {
+	case implements(k, tEnumString):
+		list = ToJValList(reflect.New(k).Interface().(EnumString).Values()...) //nolint: forcetypeassert
+
+	case implements(k, tEnum):
+		for _, raw := range reflect.New(k).Interface().(Enum).Values() { //nolint: forcetypeassert
+			var v string
+			if json.Unmarshal(raw, &v) != nil {
+				v = string(bytes.TrimSpace(raw))
+			}
+			list = append(list, v)
+		}
+
+	default:
+		return nil
+	}
+
+	SortJVal(list)
+
+	return list
+}
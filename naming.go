@@ -0,0 +1,127 @@
+package jschema
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// NamePolicy converts the go field name to the property name, it's only applied to the untagged fields.
+type NamePolicy func(name string) string
+
+var (
+	// NameSnakeCase converts "HTTPServerID" to "http_server_id".
+	NameSnakeCase NamePolicy = func(name string) string {
+		return strings.ToLower(strings.Join(splitWords(name), "_"))
+	}
+
+	// NameKebabCase converts "HTTPServerID" to "http-server-id".
+	NameKebabCase NamePolicy = func(name string) string {
+		return strings.ToLower(strings.Join(splitWords(name), "-"))
+	}
+
+	// NameCamelCase converts "HTTPServerID" to "httpServerId".
+	NameCamelCase NamePolicy = func(name string) string {
+		words := splitWords(name)
+		for i, w := range words {
+			w = strings.ToLower(w)
+			if i > 0 {
+				w = strings.ToUpper(w[:1]) + w[1:]
+			}
+			words[i] = w
+		}
+		return strings.Join(words, "")
+	}
+
+	// NameLowerCase converts "HTTPServerID" to "httpserverid".
+	NameLowerCase NamePolicy = strings.ToLower
+)
+
+// WithNamePolicy returns a copy of s that converts the untagged field names with the policy.
+// It overrides the default naming of the name tag format, such as the lowercase of yaml.
+func (s Schemas) WithNamePolicy(p NamePolicy) Schemas {
+	s.namePolicy = p
+	return s
+}
+
+// WithCaseInsensitiveNames returns a copy of s that adds a case-insensitive pattern for each property,
+// such as property "id" will also accept "ID" and "Id". It's useful for the decoders like encoding/json
+// that match the keys case-insensitively.
+func (s Schemas) WithCaseInsensitiveNames() Schemas {
+	s.caseInsensitive = true
+	return s
+}
+
+// propertyName returns the property name of the field.
+func (s Schemas) propertyName(f reflect.StructField, tag *Tag, format nameTagFormat) string {
+	if tag != nil && tag.Name != "" {
+		return tag.Name
+	}
+
+	if s.namePolicy != nil {
+		return s.namePolicy(f.Name)
+	}
+
+	if format.lowerName {
+		return strings.ToLower(f.Name)
+	}
+
+	return f.Name
+}
+
+// caseInsensitivePattern returns the regex that matches the name case-insensitively,
+// such as "^[iI][dD]$" for "id".
+func caseInsensitivePattern(name string) string {
+	b := strings.Builder{}
+	b.WriteString("^")
+
+	for _, r := range name {
+		l, u := unicode.ToLower(r), unicode.ToUpper(r)
+		if l == u {
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		} else {
+			b.WriteString("[" + string(l) + string(u) + "]")
+		}
+	}
+
+	b.WriteString("$")
+
+	return b.String()
+}
+
+// splitWords splits the go identifier into words, such as "HTTPServerID" to ["HTTP", "Server", "ID"].
+func splitWords(name string) []string {
+	words := []string{}
+	word := []rune{}
+
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = []rune{}
+		}
+	}
+
+	rs := []rune(name)
+	for i, r := range rs {
+		if r == '_' || r == '-' {
+			flush()
+			continue
+		}
+
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+
+		word = append(word, r)
+	}
+
+	flush()
+
+	return words
+}
@@ -0,0 +1,96 @@
+package jschema
+
+import (
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+)
+
+// maxRepeat is the max extra repeat count of the unbounded operators of a pattern, such as "*" and "+".
+const maxRepeat = 3
+
+// patternString returns a random string that matches the regular expression pattern.
+func patternString(r *rand.Rand, pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+
+	b := &strings.Builder{}
+	genPattern(r, b, re.Simplify())
+	return b.String(), nil
+}
+
+func genPattern(r *rand.Rand, b *strings.Builder, re *syntax.Regexp) { //nolint: cyclop
+	//nolint: exhaustive
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, c := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && r.Intn(2) == 0 {
+				c = []rune(strings.ToUpper(string(c)))[0]
+			}
+			b.WriteRune(c)
+		}
+
+	case syntax.OpCharClass:
+		b.WriteRune(classRune(r, re.Rune))
+
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		b.WriteRune(rune('a' + r.Intn(26)))
+
+	case syntax.OpCapture:
+		genPattern(r, b, re.Sub[0])
+
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			genPattern(r, b, sub)
+		}
+
+	case syntax.OpAlternate:
+		genPattern(r, b, re.Sub[r.Intn(len(re.Sub))])
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		low, high := re.Min, re.Max
+
+		switch re.Op {
+		case syntax.OpStar:
+			low, high = 0, maxRepeat
+		case syntax.OpPlus:
+			low, high = 1, 1+maxRepeat
+		case syntax.OpQuest:
+			low, high = 0, 1
+		}
+		if high < 0 {
+			high = low + maxRepeat
+		}
+
+		for i := low + r.Intn(high-low+1); i > 0; i-- {
+			genPattern(r, b, re.Sub[0])
+		}
+	}
+}
+
+// classRune returns a random rune in the ranges, the ranges are pairs of the lower and upper bounds.
+// The printable ascii runes are preferred.
+func classRune(r *rand.Rand, ranges []rune) rune {
+	ascii := []rune{}
+	for i := 0; i < len(ranges); i += 2 {
+		low, high := ranges[i], ranges[i+1]
+		if low < ' ' {
+			low = ' '
+		}
+		if high > '~' {
+			high = '~'
+		}
+		if low <= high {
+			ascii = append(ascii, low, high)
+		}
+	}
+	if len(ascii) > 0 {
+		ranges = ascii
+	}
+
+	i := r.Intn(len(ranges)/2) * 2
+	low, high := ranges[i], ranges[i+1]
+	return low + rune(r.Intn(int(high-low)+1))
+}
@@ -0,0 +1,38 @@
+package jschema
+
+// PointerPolicy decides how the pointer types are converted.
+type PointerPolicy int
+
+const (
+	// PointerNullable converts *T to anyOf [T, null], the requirement of the field is decided by the tags.
+	PointerNullable PointerPolicy = iota
+
+	// PointerOptional converts *T to T and makes the pointer field optional.
+	PointerOptional
+
+	// PointerNullableOptional converts *T to anyOf [T, null] and makes the pointer field optional.
+	PointerNullableOptional
+)
+
+func (p PointerPolicy) nullable() bool {
+	return p != PointerOptional
+}
+
+func (p PointerPolicy) optional() bool {
+	return p != PointerNullable
+}
+
+// WithPointerPolicy returns a copy of s that converts the pointers with the policy,
+// the default is [PointerNullable].
+func (s Schemas) WithPointerPolicy(p PointerPolicy) Schemas {
+	s.pointerPolicy = p
+	return s
+}
+
+// WithItemPointerPolicy returns a copy of s that converts the pointers of the array items and map values
+// with the policy, such as the *Node of []*Node. Because items can't be omitted, the [PointerOptional]
+// only removes the null. The default is [PointerNullable].
+func (s Schemas) WithItemPointerPolicy(p PointerPolicy) Schemas {
+	s.itemPointerPolicy = p
+	return s
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 type Ref struct {
@@ -43,18 +46,198 @@ func (s *Schemas) RefT(t reflect.Type) Ref {
 		s.names[id] = list
 	}
 
-	i := 0
-	if _, has := list[hash]; !has {
-		i = len(list)
+	i, has := list[hash]
+	if !has {
+		i = nextIndex(list)
 		list[hash] = i
+		s.typeNames[hash] = t.PkgPath() + "." + t.Name()
 	}
 	if i != 0 {
 		id = fmt.Sprintf("%s%d", id, i)
 	}
 
+	if to, has := s.renamed[hash]; has {
+		id = to
+	}
+
 	return Ref{s.refPrefix, t.PkgPath(), t.Name(), hash, id}
 }
 
+// Refs returns the refs of all the defined types, they are sorted by the ID.
+func (s Schemas) Refs() []Ref {
+	list := make([]Ref, 0, len(s.refs))
+	for _, r := range s.refs {
+		list = append(list, r)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+
+	return list
+}
+
+// Load adds the precomputed schema of the ref into the schema list,
+// the type of the ref won't be converted again by [Schemas.DefineT].
+// It's usually called by the code generated from [Schemas.Refs] and [Schemas.JSON].
+func (s Schemas) Load(r Ref, scm *Schema) {
+	base := regTrimGeneric.ReplaceAllString(r.Name, "")
+
+	list, ok := s.names[base]
+	if !ok {
+		list = map[string]int{}
+		s.names[base] = list
+	}
+
+	suffix := strings.TrimPrefix(r.ID, base)
+	i, err := strconv.Atoi(suffix)
+	if suffix == "" || (err == nil && suffix == strconv.Itoa(i) && i > 0) {
+		list[r.Hash] = i
+	} else {
+		s.renamed[r.Hash] = r.ID
+		s.reserve(r.ID, r.Hash)
+	}
+
+	s.add(r, scm)
+}
+
+// nextIndex returns the first index of the name list that no type uses, the renamed ids may leave gaps in it.
+func nextIndex(list map[string]int) int {
+	used := map[int]bool{}
+	for _, i := range list {
+		used[i] = true
+	}
+
+	i := len(list)
+	for used[i] {
+		i++
+	}
+	return i
+}
+
+// splitID splits the id into the base name and the index that [Schemas.RefT] appends, such as "Node2" to "Node" and 2.
+func splitID(id string) (string, int) {
+	base := strings.TrimRight(id, "0123456789")
+	i, err := strconv.Atoi(id[len(base):])
+	if base == "" || err != nil || id[len(base):] != strconv.Itoa(i) || i == 0 {
+		return id, 0
+	}
+	return base, i
+}
+
+// taken reports whether [Schemas.RefT] has given the id to a type other than the one of the hash.
+func (s Schemas) taken(id, hash string) bool {
+	used := func(name string, index int) bool {
+		for h, i := range s.names[name] {
+			if h != hash && i == index {
+				return true
+			}
+		}
+		return false
+	}
+
+	base, i := splitID(id)
+	return used(id, 0) || (i > 0 && used(base, i))
+}
+
+// reserve marks the id as used by the type of the hash, so [Schemas.RefT] won't give the id to other types.
+func (s Schemas) reserve(id, hash string) {
+	add := func(name string, i int) {
+		if s.names[name] == nil {
+			s.names[name] = map[string]int{}
+		}
+		s.names[name][hash] = i
+	}
+
+	add(id, 0)
+	if base, i := splitID(id); i > 0 {
+		add(base, i)
+	}
+}
+
+// renumber reassigns the indexes of the types that share a name by the order of their package paths and names,
+// so the IDs don't depend on the order the types are referred. The definitions, the $refs in the schema list and
+// the handlers are moved to the new IDs. The names that have loaded or renamed types are kept as they are.
+func (s Schemas) renumber() {
+	moved := map[string]string{}
+
+	for base, list := range s.names {
+		hashes := make([]string, 0, len(list))
+		for h := range list {
+			if _, has := s.typeNames[h]; !has {
+				hashes = nil
+				break
+			}
+			if _, has := s.renamed[h]; has {
+				hashes = nil
+				break
+			}
+			hashes = append(hashes, h)
+		}
+		if len(hashes) < 2 {
+			continue
+		}
+
+		sort.Slice(hashes, func(i, j int) bool {
+			return s.typeNames[hashes[i]] < s.typeNames[hashes[j]]
+		})
+
+		for i, h := range hashes {
+			if list[h] != i {
+				list[h] = i
+				moved[h] = indexedID(base, i)
+			}
+		}
+	}
+
+	if len(moved) == 0 {
+		return
+	}
+
+	types, refs, goTypes := Types{}, map[string]Ref{}, map[string]reflect.Type{}
+	for id, r := range s.refs {
+		if to, has := moved[r.Hash]; has {
+			types[to], goTypes[to] = s.types[id], s.goTypes[id]
+			r.ID = to
+			refs[to] = r
+			s.remove(id)
+		}
+	}
+	for id, r := range refs {
+		s.types[id], s.refs[id] = types[id], r
+		if goTypes[id] != nil {
+			s.goTypes[id] = goTypes[id]
+		}
+	}
+
+	for _, d := range s.types {
+		d.each(func(ss *Schema) {
+			if ss.Ref == nil {
+				return
+			}
+			if to, has := moved[ss.Ref.Hash]; has && ss.Ref.Unique() {
+				ss.Ref.ID = to
+			}
+		})
+	}
+
+	for hr, h := range s.handlers {
+		if to, has := moved[hr.Hash]; has {
+			delete(s.handlers, hr)
+			hr.ID = to
+			s.handlers[hr] = h
+		}
+	}
+}
+
+// indexedID is the reverse of [splitID].
+func indexedID(base string, i int) string {
+	if i == 0 {
+		return base
+	}
+	return base + strconv.Itoa(i)
+}
+
 func (r Ref) String() string {
 	return r.Package + "." + r.Name
 }
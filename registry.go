@@ -0,0 +1,244 @@
+package jschema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Get returns the definition of the id in the schema list, it returns nil if not found.
+// Use [Schemas.Refs] to list the definitions in a stable order.
+func (s Schemas) Get(id string) *Schema {
+	return s.types[id]
+}
+
+// GoType returns the go type of the definition of the id, it returns nil if the definition is not converted from a go type,
+// such as the ones added by [Schemas.Load].
+func (s Schemas) GoType(id string) reflect.Type {
+	return s.goTypes[id]
+}
+
+// Remove removes the definition of v from the schema list, v can be a value, a [Ref] or a [Schema] that has $ref.
+// The $refs to it are not changed, use [Schemas.ReferencedBy] to find them.
+// If the type of v is defined again it will get the same ID.
+func (s Schemas) Remove(v interface{}) {
+	s.remove(s.refOf(v).ID)
+}
+
+// Rename changes the ID of the definition of v to id, and rewrites every $ref in the schema list that points to it.
+// The later refs of the type of v, such as the ones from [Schemas.RefT], will also use the id.
+// The schemas that are not in the schema list, such as the ones returned by [Schemas.Define], won't be rewritten.
+func (s Schemas) Rename(v interface{}, id string) error {
+	from := s.refOf(v).ID
+
+	r, has := s.refs[from]
+	if !has {
+		return fmt.Errorf("jschema: no definition for %q", from)
+	}
+
+	scm := s.types[r.ID]
+
+	if _, has := s.types[id]; has {
+		return fmt.Errorf("jschema: definition %q already exists", id)
+	}
+	if s.taken(id, r.Hash) {
+		return fmt.Errorf("jschema: id %q is used by another type", id)
+	}
+
+	for _, d := range s.types {
+		d.each(func(ss *Schema) {
+			if ss.Ref != nil && ss.Ref.ID == r.ID {
+				ss.Ref.ID = id
+			}
+		})
+	}
+
+	for hr, h := range s.handlers {
+		if hr.Hash == r.Hash {
+			delete(s.handlers, hr)
+			hr.ID = id
+			s.handlers[hr] = h
+		}
+	}
+
+	t := s.goTypes[r.ID]
+	s.remove(r.ID)
+
+	s.renamed[r.Hash] = id
+	s.reserve(id, r.Hash)
+	r.ID = id
+	s.add(r, scm)
+	if t != nil {
+		s.goTypes[id] = t
+	}
+
+	return nil
+}
+
+// References returns the refs that the definition of v directly points to, they are sorted by the ID.
+// v can be a value, a [Ref] or a [Schema] that has $ref.
+func (s Schemas) References(v interface{}) []Ref {
+	r := s.refOf(v)
+
+	ids := map[string]bool{}
+	s.types[r.ID].each(func(ss *Schema) {
+		if ss.Ref != nil {
+			ids[ss.Ref.ID] = true
+		}
+	})
+
+	return s.sortedRefs(ids)
+}
+
+// ReferencedBy returns the refs of the definitions that directly point to v, they are sorted by the ID.
+// v can be a value, a [Ref] or a [Schema] that has $ref.
+func (s Schemas) ReferencedBy(v interface{}) []Ref {
+	r := s.refOf(v)
+
+	ids := map[string]bool{}
+	for id, d := range s.types {
+		d.each(func(ss *Schema) {
+			if ss.Ref != nil && ss.Ref.ID == r.ID {
+				ids[id] = true
+			}
+		})
+	}
+
+	return s.sortedRefs(ids)
+}
+
+// Prune removes the definitions that are not reachable from the roots, it returns the removed refs.
+// The roots can be values, [Ref]s or [Schema]s, such as the ones returned by [Schemas.Define].
+func (s Schemas) Prune(roots ...interface{}) []Ref {
+	list := []*Schema{}
+	for _, root := range roots {
+		if scm, ok := root.(*Schema); ok {
+			list = append(list, scm)
+			continue
+		}
+
+		r := s.refOf(root)
+		list = append(list, &Schema{Ref: &r})
+	}
+
+	reachable := s.reachable(list...)
+
+	removed := map[string]bool{}
+	for id := range s.types {
+		if !reachable[id] {
+			removed[id] = true
+		}
+	}
+
+	refs := s.sortedRefs(removed)
+
+	for id := range removed {
+		s.remove(id)
+	}
+
+	return refs
+}
+
+func (s Schemas) remove(id string) {
+	delete(s.types, id)
+	delete(s.refs, id)
+	delete(s.goTypes, id)
+}
+
+// reachable returns the IDs of the definitions that are transitively referenced by the list.
+func (s Schemas) reachable(list ...*Schema) map[string]bool {
+	ids := map[string]bool{}
+
+	var visit func(scm *Schema)
+	visit = func(scm *Schema) {
+		scm.each(func(ss *Schema) {
+			if ss.Ref == nil || ids[ss.Ref.ID] {
+				return
+			}
+
+			if d, has := s.types[ss.Ref.ID]; has {
+				ids[ss.Ref.ID] = true
+				visit(d)
+			}
+		})
+	}
+
+	for _, scm := range list {
+		visit(scm)
+	}
+
+	return ids
+}
+
+// refOf returns the ref of v, v can be a value, a [Ref] or a [Schema] that has $ref.
+func (s Schemas) refOf(v interface{}) Ref {
+	switch v := v.(type) {
+	case Ref:
+		return v
+	case *Ref:
+		return *v
+	case *Schema:
+		if v.Ref != nil {
+			return *v.Ref
+		}
+	}
+
+	return s.Ref(v)
+}
+
+// sortedRefs returns the refs of the ids that are in the schema list, they are sorted by the ID.
+func (s Schemas) sortedRefs(ids map[string]bool) []Ref {
+	list := []Ref{}
+	for id := range ids {
+		if r, has := s.refs[id]; has {
+			list = append(list, r)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+
+	return list
+}
+
+// each calls fn with s and all its sub schemas recursively, it doesn't follow the $ref.
+func (s *Schema) each(fn func(*Schema)) {
+	if s == nil {
+		return
+	}
+
+	fn(s)
+
+	for _, ss := range s.subs() {
+		ss.each(fn)
+	}
+}
+
+// subs returns the direct sub schemas of s.
+func (s *Schema) subs() []*Schema {
+	list := []*Schema{}
+	add := func(ss ...*Schema) {
+		for _, sub := range ss {
+			if sub != nil {
+				list = append(list, sub)
+			}
+		}
+	}
+
+	add(s.AnyOf...)
+	for _, p := range s.Properties {
+		add(p)
+	}
+	for _, p := range s.PatternProperties {
+		add(p)
+	}
+	add(s.PropertyNames, s.AdditionalPropertiesSchema)
+	add(s.PrefixItems...)
+	add(s.Items)
+	for _, p := range s.Defs {
+		add(p)
+	}
+
+	return list
+}
@@ -0,0 +1,141 @@
+package jschema_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+)
+
+func TestRegistry(t *testing.T) {
+	g := got.T(t)
+
+	type Leaf struct {
+		V int
+	}
+
+	type Node struct {
+		Leaf     Leaf
+		Children []Node
+	}
+
+	type Other struct {
+		Leaf *Leaf
+	}
+
+	s := jschema.New("")
+	s.Define(Node{})
+	s.Define(Other{})
+
+	ids := func(list []jschema.Ref) []string {
+		out := []string{}
+		for _, r := range list {
+			out = append(out, r.ID)
+		}
+		return out
+	}
+
+	g.Eq(ids(s.Refs()), []string{"Leaf", "Node", "Other"})
+	g.Eq(s.Get("Leaf").Title, "Leaf")
+	g.Eq(s.GoType("Leaf"), reflect.TypeOf(Leaf{}))
+	g.Nil(s.Get("Nope"))
+
+	g.Eq(ids(s.References(Node{})), []string{"Leaf", "Node"})
+	g.Eq(ids(s.ReferencedBy(Leaf{})), []string{"Node", "Other"})
+	g.Eq(ids(s.ReferencedBy(s.Define(Node{}))), []string{"Node"})
+
+	g.E(s.Rename(Leaf{}, "Item"))
+	g.Eq(ids(s.Refs()), []string{"Item", "Node", "Other"})
+	g.Eq(s.GoType("Item"), reflect.TypeOf(Leaf{}))
+	g.Nil(s.GoType("Leaf"))
+	g.Eq(s.Get("Node").Properties["Leaf"].Ref.ID, "Item")
+	g.Eq(s.Ref(Leaf{}).ID, "Item")
+	g.Eq(s.Define(Leaf{}).Ref.ID, "Item")
+	g.Eq(ids(s.Refs()), []string{"Item", "Node", "Other"})
+	g.Has(s.String(), `"$ref": "#/$defs/Item"`)
+
+	l := jschema.New("")
+	for _, r := range s.Refs() {
+		l.Load(r, s.Get(r.ID))
+	}
+	g.Eq(l.Ref(Leaf{}).ID, "Item")
+	g.Eq(l.String(), s.String())
+
+	g.Eq(s.Rename(Leaf{}, "Node").Error(), `jschema: definition "Node" already exists`)
+	g.Eq(s.Rename(1, "X").Error(), `jschema: no definition for "int"`)
+
+	g.Eq(ids(s.Prune(Other{})), []string{"Node"})
+	g.Eq(ids(s.Refs()), []string{"Item", "Other"})
+
+	s.Remove(s.Define(Other{}))
+	g.Eq(ids(s.Refs()), []string{"Item"})
+	g.Eq(s.Define(Other{}).Ref.ID, "Other")
+	g.Eq(ids(s.Refs()), []string{"Item", "Other"})
+}
+
+func TestRenameReserve(t *testing.T) {
+	g := got.T(t)
+
+	type Leaf struct {
+		V int
+	}
+
+	type Time struct {
+		V string
+	}
+
+	type Node struct{}
+
+	s := jschema.New("")
+	s.Define(Leaf{})
+	g.E(s.Rename(Leaf{}, "Time"))
+
+	g.Eq(s.Define(time.Time{}).Ref.ID, "Time1")
+	g.Eq(s.Get("Time1").Title, "Time")
+	g.Eq(s.Get("Time").Properties["V"].Type, jschema.TypeInteger)
+	g.Eq(s.Ref(Time{}).ID, "Time2")
+
+	s.Define(Node{})
+	g.Eq(s.Rename(Node{}, "Time2").Error(), `jschema: id "Time2" is used by another type`)
+	g.Eq(s.Rename(Node{}, "Time1").Error(), `jschema: definition "Time1" already exists`)
+	g.E(s.Rename(Node{}, "Time3"))
+	g.Eq(s.Define(Time{}).Ref.ID, "Time2")
+
+	// The ids loaded from a renamed schema list are reserved too.
+	l := jschema.New("")
+	for _, r := range s.Refs() {
+		l.Load(r, s.Get(r.ID))
+	}
+	g.Eq(l.Ref(Leaf{}).ID, "Time")
+	g.Eq(l.Ref(time.Time{}).ID, "Time1")
+	g.Eq(l.String(), s.String())
+}
+
+func TestClone(t *testing.T) {
+	g := got.T(t)
+
+	type Leaf struct {
+		V int
+	}
+
+	type Node struct {
+		Leaf Leaf
+	}
+
+	s := jschema.New("")
+	s.Define(Leaf{})
+	str := s.String()
+
+	c := s.Clone()
+	c.Define(Node{})
+	c.Describe(Leaf{}, "leaf")
+	g.E(c.Rename(Leaf{}, "Item"))
+
+	g.Eq(s.String(), str)
+	g.Eq(s.Ref(Leaf{}).ID, "Leaf")
+	g.Eq(c.Ref(Leaf{}).ID, "Item")
+	g.Nil(s.Get("Node"))
+	g.Eq(c.Get("Item").Description, "leaf")
+}
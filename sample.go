@@ -0,0 +1,361 @@
+package jschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SampleOptions is the options for [Schemas.Sample].
+type SampleOptions struct {
+	// Seed of the random generator, the same seed generates the same sample.
+	Seed int64
+
+	// MaxDepth is the depth that the optional properties, extra array items and nullable values stop to grow,
+	// it's to limit the size of the recursive types. The default is 3.
+	MaxDepth int
+}
+
+// maxSampleDepth is the depth limit of the required nesting beyond [SampleOptions.MaxDepth],
+// such as a recursive type that requires itself.
+const maxSampleDepth = 32
+
+// Sample generates a random json value that is valid for the schema of the ref, it's for the docs and mock servers.
+// It prefers the Examples and Default, respects the Enum, Min, Max, MinLen, MaxLen, Pattern, array bounds, Required,
+// and the Format of "email", "uuid", "date-time", "date", "time", "uri", "hostname", "ipv4".
+func (s Schemas) Sample(ref Ref, opts SampleOptions) ([]byte, error) {
+	return s.SampleSchema(&Schema{Ref: &ref}, opts)
+}
+
+// SampleSchema is like [Schemas.Sample] but for a schema that may not be in the schema list,
+// such as the schema of a struct field. The $refs of it are resolved by s.
+func (s Schemas) SampleSchema(scm *Schema, opts SampleOptions) ([]byte, error) {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = 3
+	}
+
+	g := &sampler{s: s, r: rand.New(rand.NewSource(opts.Seed)), opts: opts} //nolint: gosec
+
+	v, err := g.sample(scm, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+type sampler struct {
+	s    Schemas
+	r    *rand.Rand
+	opts SampleOptions
+}
+
+func (g *sampler) sample(scm *Schema, depth int) (interface{}, error) { //nolint: cyclop
+	if depth > g.opts.MaxDepth+maxSampleDepth {
+		return nil, fmt.Errorf("jschema: the sample is deeper than %d", depth)
+	}
+
+	if scm.Ref != nil {
+		def, has := g.s.types[scm.Ref.ID]
+		if !has {
+			return nil, fmt.Errorf("jschema: no definition for %q", scm.Ref.ID)
+		}
+
+		site := *scm
+		site.Ref = nil
+		def = def.Clone()
+		def.merge(&site)
+		scm = def
+	}
+
+	grow := depth < g.opts.MaxDepth
+
+	switch {
+	case len(scm.Examples) > 0:
+		return scm.Examples[g.r.Intn(len(scm.Examples))], nil
+	case scm.Default != nil:
+		return scm.Default, nil
+	case len(scm.Enum) > 0:
+		return scm.Enum[g.r.Intn(len(scm.Enum))], nil
+	case len(scm.AnyOf) > 0:
+		if !grow {
+			for _, s := range scm.AnyOf {
+				if s.Type == TypeNull {
+					return nil, nil
+				}
+			}
+		}
+		// The keywords of the wrapper apply to the branch, such as the min of a nullable pointer field.
+		site := *scm
+		site.AnyOf = nil
+		branch := scm.AnyOf[g.r.Intn(len(scm.AnyOf))].Clone()
+		branch.merge(&site)
+		return g.sample(branch, depth)
+	}
+
+	//nolint: exhaustive
+	switch scm.Type {
+	case TypeBool:
+		return g.r.Intn(2) == 1, nil
+
+	case TypeInteger:
+		minimum, maximum := g.bounds(scm.Min, scm.Max)
+		low := math.Max(math.Ceil(minimum), -maxSampleInt)
+		high := math.Min(math.Floor(maximum), maxSampleInt)
+		if low > high {
+			return nil, fmt.Errorf("jschema: no int64 in [%s, %s]", formatNum(minimum), formatNum(maximum))
+		}
+		// Compute in float to avoid the overflow of the range, such as [-2^62, 2^62].
+		return int64(math.Min(low+math.Floor(g.r.Float64()*(high-low+1)), high)), nil
+
+	case TypeNumber:
+		low, high := g.bounds(scm.Min, scm.Max)
+		if low > high {
+			return nil, fmt.Errorf("jschema: no number in [%s, %s]", formatNum(low), formatNum(high))
+		}
+		return math.Max(low, math.Min(high, math.Round((low+g.r.Float64()*(high-low))*100)/100)), nil
+
+	case TypeString:
+		return g.str(scm)
+
+	case TypeArray:
+		return g.array(scm, depth, grow)
+
+	case TypeObject:
+		return g.object(scm, depth, grow)
+
+	case TypeNull:
+		return nil, nil
+
+	case "":
+		return []interface{}{"a", 1.0, true, nil}[g.r.Intn(4)], nil
+	}
+
+	return nil, nil
+}
+
+// maxSampleInt is the max float64 that can be converted to int64.
+const maxSampleInt = float64(math.MaxInt64 - 1023)
+
+// bounds returns the inclusive range of a number, the unbounded side is 100 away from the other side.
+func (g *sampler) bounds(minimum, maximum *float64) (float64, float64) {
+	low, high := 0.0, 100.0
+	switch {
+	case minimum != nil && maximum != nil:
+		low, high = *minimum, *maximum
+	case minimum != nil:
+		low, high = *minimum, *minimum+100
+	case maximum != nil:
+		low, high = math.Min(0, *maximum), *maximum
+	}
+	return low, high
+}
+
+func (g *sampler) str(scm *Schema) (string, error) {
+	minLen, maxLen := 0, 12
+	if scm.MinLen != nil {
+		minLen = int(*scm.MinLen)
+		if maxLen < minLen {
+			maxLen = minLen + 12
+		}
+	}
+	if scm.MaxLen != nil {
+		maxLen = int(*scm.MaxLen)
+	}
+	if minLen > maxLen {
+		return "", fmt.Errorf("jschema: no string of the length in [%d, %d]", minLen, maxLen)
+	}
+
+	var str string
+
+	// The pattern may generate strings that are out of the length range, retry for a few times.
+	for i := 0; i < 10; i++ {
+		if scm.Pattern != "" {
+			p, err := patternString(g.r, scm.Pattern)
+			if err != nil {
+				return "", err
+			}
+			str = p
+		} else if f, has := g.format(scm.Format); has {
+			str = f
+		} else {
+			str = g.letters(minLen + g.r.Intn(maxLen-minLen+1))
+		}
+
+		l := float64(len([]rune(str)))
+		if (scm.MinLen == nil || l >= *scm.MinLen) && (scm.MaxLen == nil || l <= *scm.MaxLen) {
+			return str, nil
+		}
+	}
+
+	return "", fmt.Errorf("jschema: failed to sample a string within the length limits, the last one is %q", str)
+}
+
+func (g *sampler) format(format string) (string, bool) {
+	switch format {
+	case "email":
+		return g.letters(6) + "@example.com", true
+	case "uuid":
+		b := make([]byte, 16)
+		_, _ = g.r.Read(b)
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), true
+	case "date-time":
+		return g.time().Format(time.RFC3339), true
+	case "date":
+		return g.time().Format("2006-01-02"), true
+	case "time":
+		return g.time().Format("15:04:05Z07:00"), true
+	case "uri":
+		return "https://example.com/" + g.letters(6), true
+	case "hostname":
+		return g.letters(6) + ".example.com", true
+	case "ipv4":
+		return fmt.Sprintf("192.168.%d.%d", g.r.Intn(256), g.r.Intn(256)), true
+	}
+	return "", false
+}
+
+func (g *sampler) time() time.Time {
+	return time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(g.r.Int63n(int64(30 * 365 * 24 * time.Hour))))
+}
+
+func (g *sampler) letters(n int) string {
+	const letters = "abcdefghijklmnopqrstuvwxyz"
+
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letters[g.r.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+func (g *sampler) array(scm *Schema, depth int, grow bool) (interface{}, error) {
+	list := []interface{}{}
+
+	for _, item := range scm.PrefixItems {
+		v, err := g.sample(item, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+
+	if scm.Items == nil {
+		return list, nil
+	}
+
+	minItems, maxItems := 0, 3
+	if scm.MinItems != nil {
+		minItems = *scm.MinItems
+		if maxItems < minItems {
+			maxItems = minItems
+		}
+	}
+	if scm.MaxItems != nil {
+		maxItems = *scm.MaxItems
+	}
+	if !grow {
+		maxItems = minItems
+	}
+
+	for i := minItems + g.r.Intn(maxItems-minItems+1); i > 0; i-- {
+		v, err := g.sample(scm.Items, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+
+	return list, nil
+}
+
+func (g *sampler) object(scm *Schema, depth int, grow bool) (interface{}, error) {
+	obj := map[string]interface{}{}
+
+	names := []string{}
+	for name := range scm.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !scm.Required.Has(name) && (!grow || g.r.Intn(2) == 0) {
+			continue
+		}
+
+		v, err := g.sample(scm.Properties[name], depth+1)
+		if err != nil {
+			return nil, err
+		}
+		obj[name] = v
+	}
+
+	if !grow {
+		return obj, nil
+	}
+
+	patterns := []string{}
+	for p := range scm.PatternProperties {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+
+	for _, p := range patterns {
+		if p == "" || g.r.Intn(2) == 0 {
+			continue
+		}
+
+		key, err := patternString(g.r, p)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := g.sample(scm.PatternProperties[p], depth+1)
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = v
+	}
+
+	if values := scm.mapValue(); values != nil {
+		for i := g.r.Intn(3); i > 0; i-- {
+			key, err := g.key(scm.PropertyNames)
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := g.sample(values, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = v
+		}
+	}
+
+	return obj, nil
+}
+
+// key returns a random property name that is valid for the propertyNames schema.
+func (g *sampler) key(names *Schema) (string, error) {
+	if names == nil {
+		return g.letters(1 + g.r.Intn(8)), nil
+	}
+
+	if len(names.Enum) > 0 {
+		return fmt.Sprint(names.Enum[g.r.Intn(len(names.Enum))]), nil
+	}
+
+	if names.MinLen == nil && names.Pattern == "" && names.Format == "" {
+		names = &Schema{MinLen: Ptr(1.0), MaxLen: names.MaxLen}
+	}
+
+	key, err := g.str(names)
+
+	return strings.TrimSpace(key), err
+}
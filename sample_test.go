@@ -0,0 +1,153 @@
+package jschema_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+)
+
+func TestSample(t *testing.T) {
+	g := got.T(t)
+
+	type Color string
+
+	type Node struct {
+		Name     string   `json:"name" minLen:"3" maxLen:"5"`
+		Code     string   `json:"code" pattern:"^[A-Z]{2}-\\d{3}$"`
+		Email    string   `json:"email" format:"email"`
+		ID       string   `json:"id" format:"uuid"`
+		Created  string   `json:"created" format:"date-time"`
+		Link     string   `json:"link" format:"uri"`
+		Age      int      `json:"age" min:"1" max:"3"`
+		Score    float64  `json:"score" min:"-1" max:"1"`
+		Tags     []string `json:"tags" minItems:"1" maxItems:"2"`
+		Color    Color    `json:"color"`
+		Kind     string   `json:"kind" default:"\"leaf\""`
+		Children []*Node  `json:"children,omitempty"`
+		Parent   *Node    `json:"parent"`
+
+		Labels map[string]int `json:"labels"`
+	}
+
+	s := jschema.New("")
+	s.Define(Color(""))
+	s.SetSchema(Color(""), &jschema.Schema{Enum: jschema.ToJValList("red", "blue")})
+	s.Define(Node{})
+	ref := s.Ref(Node{})
+
+	for seed := int64(0); seed < 30; seed++ {
+		b, err := s.Sample(ref, jschema.SampleOptions{Seed: seed})
+		g.E(err)
+		g.E(s.ValidateJSON(ref, b))
+
+		// The same seed generates the same sample.
+		b2, _ := s.Sample(ref, jschema.SampleOptions{Seed: seed})
+		g.Eq(string(b2), string(b))
+
+		var n Node
+		g.E(json.Unmarshal(b, &n))
+		g.Eq(n.Kind, "leaf")
+	}
+}
+
+func TestSamplePointer(t *testing.T) {
+	g := got.T(t)
+
+	type Node struct {
+		N *int     `json:"n" min:"500" max:"600"`
+		S *string  `json:"s" minLen:"2" maxLen:"2"`
+		L *[]int   `json:"l" minItems:"2"`
+		P **string `json:"p" pattern:"^x+$"`
+	}
+
+	s := jschema.New("")
+	s.Define(Node{})
+
+	for seed := int64(0); seed < 50; seed++ {
+		b, err := s.Sample(s.Ref(Node{}), jschema.SampleOptions{Seed: seed})
+		g.E(err)
+		g.Desc("%s", b).E(s.ValidateJSON(s.Ref(Node{}), b))
+	}
+}
+
+func TestSampleSchema(t *testing.T) {
+	g := got.T(t)
+
+	s := jschema.New("")
+
+	b, err := s.SampleSchema(&jschema.Schema{Type: jschema.TypeString, Pattern: "^t-[a-z]{3}$"}, jschema.SampleOptions{})
+	g.E(err)
+	g.Regex(`^"t-[a-z]{3}"$`, string(b))
+
+	_, err = s.SampleSchema(&jschema.Schema{Ref: &jschema.Ref{ID: "X"}}, jschema.SampleOptions{})
+	g.Eq(err.Error(), `jschema: no definition for "X"`)
+}
+
+func TestSampleExamples(t *testing.T) {
+	g := got.T(t)
+
+	type A struct {
+		ID int `json:"id"`
+	}
+
+	s := jschema.New("")
+	s.Define(A{})
+	ref := s.Ref(A{})
+	g.E(s.AddExample(A{ID: 10}))
+
+	b, err := s.Sample(ref, jschema.SampleOptions{})
+	g.E(err)
+	g.Eq(string(b), `{"id":10}`)
+}
+
+func TestSampleErr(t *testing.T) {
+	g := got.T(t)
+
+	type A struct {
+		Self int `json:"self"`
+	}
+
+	s := jschema.New("")
+	s.Define(A{})
+	ref := s.Ref(A{})
+
+	s.PeakSchema(A{}).Properties["self"] = &jschema.Schema{Ref: &ref}
+
+	_, err := s.Sample(ref, jschema.SampleOptions{})
+	g.Has(err.Error(), "jschema: the sample is deeper than")
+
+	_, err = s.Sample(jschema.Ref{ID: "X"}, jschema.SampleOptions{})
+	g.Eq(err.Error(), `jschema: no definition for "X"`)
+
+	sample := func(scm *jschema.Schema) error {
+		s := jschema.New("")
+		ref := jschema.Ref{Package: "test", Name: "X", ID: "X"}
+		s.Load(ref, scm)
+		_, err := s.Sample(ref, jschema.SampleOptions{})
+		return err
+	}
+
+	g.Eq(sample(&jschema.Schema{Type: jschema.TypeInteger, Min: jschema.Ptr(0.5), Max: jschema.Ptr(0.7)}).Error(),
+		"jschema: no int64 in [0.5, 0.7]")
+	g.Eq(sample(&jschema.Schema{Type: jschema.TypeInteger, Min: jschema.Ptr(1e19)}).Error(),
+		"jschema: no int64 in [1e+19, 1e+19]")
+	g.Eq(sample(&jschema.Schema{Type: jschema.TypeNumber, Min: jschema.Ptr(2.0), Max: jschema.Ptr(1.0)}).Error(),
+		"jschema: no number in [2, 1]")
+	g.Eq(sample(&jschema.Schema{Type: jschema.TypeString, MinLen: jschema.Ptr(3.0), MaxLen: jschema.Ptr(2.0)}).Error(),
+		"jschema: no string of the length in [3, 2]")
+	g.Eq(sample(&jschema.Schema{Type: jschema.TypeString, MinLen: jschema.Ptr(3.0), Pattern: "^ab$"}).Error(),
+		`jschema: failed to sample a string within the length limits, the last one is "ab"`)
+
+	for seed := int64(0); seed < 20; seed++ {
+		s := jschema.New("")
+		ref := jschema.Ref{Package: "test", Name: "X", ID: "X"}
+		s.Load(ref, &jschema.Schema{Type: jschema.TypeInteger, Min: jschema.Ptr(-math.Pow(2, 63)), Max: jschema.Ptr(math.Pow(2, 63))})
+		b, err := s.Sample(ref, jschema.SampleOptions{Seed: seed})
+		g.E(err)
+		var i int64
+		g.E(json.Unmarshal(b, &i))
+	}
+}
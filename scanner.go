@@ -0,0 +1,300 @@
+package jschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// maxDepth is the max nesting depth of the json values and the schemas to validate, it's the same as encoding/json.
+const maxDepth = 10000
+
+// scanner reads the json values from data without decoding them into the go values.
+type scanner struct {
+	data  []byte
+	i     int
+	depth int
+}
+
+// enter increases the nesting depth for an object or array, call leave to restore it.
+func (s *scanner) enter() error {
+	if s.depth >= maxDepth {
+		return fmt.Errorf("jschema: the json is nested deeper than %d", maxDepth)
+	}
+	s.depth++
+	return nil
+}
+
+func (s *scanner) leave() {
+	s.depth--
+}
+
+func (s *scanner) err() error {
+	if s.i >= len(s.data) {
+		return fmt.Errorf("jschema: unexpected end of json input")
+	}
+	return fmt.Errorf("jschema: invalid character %q at offset %d of the json", s.data[s.i], s.i)
+}
+
+func (s *scanner) ws() {
+	for s.i < len(s.data) {
+		switch s.data[s.i] {
+		case ' ', '\t', '\n', '\r':
+			s.i++
+		default:
+			return
+		}
+	}
+}
+
+// end returns error if there's any non-space character left.
+func (s *scanner) end() error {
+	s.ws()
+	if s.i < len(s.data) {
+		return s.err()
+	}
+	return nil
+}
+
+// kind returns the type of the next value without consuming it.
+func (s *scanner) kind() (SchemaType, error) {
+	s.ws()
+	if s.i >= len(s.data) {
+		return "", s.err()
+	}
+
+	switch c := s.data[s.i]; {
+	case c == '{':
+		return TypeObject, nil
+	case c == '[':
+		return TypeArray, nil
+	case c == '"':
+		return TypeString, nil
+	case c == 't' || c == 'f':
+		return TypeBool, nil
+	case c == 'n':
+		return TypeNull, nil
+	case c == '-' || c >= '0' && c <= '9':
+		return TypeNumber, nil
+	}
+
+	return "", s.err()
+}
+
+// skip consumes the next value.
+func (s *scanner) skip() error {
+	t, err := s.kind()
+	if err != nil {
+		return err
+	}
+
+	//nolint: exhaustive
+	switch t {
+	case TypeObject:
+		return s.object(func(string, int) error { return s.skip() })
+	case TypeArray:
+		return s.array(func(int) error { return s.skip() })
+	case TypeString:
+		_, err = s.str()
+	case TypeNumber:
+		_, err = s.num()
+	case TypeBool:
+		_, err = s.bool()
+	case TypeNull:
+		err = s.lit("null")
+	}
+
+	return err
+}
+
+func (s *scanner) lit(word string) error {
+	if len(s.data)-s.i < len(word) || string(s.data[s.i:s.i+len(word)]) != word {
+		return s.err()
+	}
+	s.i += len(word)
+	return nil
+}
+
+func (s *scanner) bool() (bool, error) {
+	if s.data[s.i] == 't' {
+		return true, s.lit("true")
+	}
+	return false, s.lit("false")
+}
+
+// str consumes a string, the strings without escapes are not copied twice.
+func (s *scanner) str() (string, error) {
+	start := s.i
+	s.i++
+
+	escaped := false
+	for s.i < len(s.data) {
+		switch c := s.data[s.i]; {
+		case c == '"':
+			s.i++
+			if !escaped {
+				return string(s.data[start+1 : s.i-1]), nil
+			}
+
+			var str string
+			if json.Unmarshal(s.data[start:s.i], &str) != nil {
+				s.i = start
+				return "", s.err()
+			}
+			return str, nil
+
+		case c == '\\':
+			escaped = true
+			s.i += 2
+
+		case c < ' ':
+			return "", s.err()
+
+		default:
+			s.i++
+		}
+	}
+
+	return "", s.err()
+}
+
+// num consumes a number that follows the json number grammar.
+func (s *scanner) num() (float64, error) {
+	start := s.i
+
+	digits := func() bool {
+		from := s.i
+		for s.i < len(s.data) && s.data[s.i] >= '0' && s.data[s.i] <= '9' {
+			s.i++
+		}
+		return s.i > from
+	}
+
+	if s.data[s.i] == '-' {
+		s.i++
+	}
+
+	if s.i < len(s.data) && s.data[s.i] == '0' {
+		s.i++
+	} else if !digits() {
+		return 0, s.err()
+	}
+
+	if s.i < len(s.data) && s.data[s.i] == '.' {
+		s.i++
+		if !digits() {
+			return 0, s.err()
+		}
+	}
+
+	if s.i < len(s.data) && (s.data[s.i] == 'e' || s.data[s.i] == 'E') {
+		s.i++
+		if s.i < len(s.data) && (s.data[s.i] == '+' || s.data[s.i] == '-') {
+			s.i++
+		}
+		if !digits() {
+			return 0, s.err()
+		}
+	}
+
+	f, err := strconv.ParseFloat(string(s.data[start:s.i]), 64)
+	if err != nil {
+		return 0, fmt.Errorf("jschema: invalid number %s of the json: %w", s.data[start:s.i], err)
+	}
+
+	return f, nil
+}
+
+// object consumes an object, fn is called with the key and the offset of the key for each member,
+// it must consume the member value.
+func (s *scanner) object(fn func(key string, offset int) error) error {
+	err := s.enter()
+	if err != nil {
+		return err
+	}
+	defer s.leave()
+
+	s.i++
+
+	s.ws()
+	if s.i < len(s.data) && s.data[s.i] == '}' {
+		s.i++
+		return nil
+	}
+
+	for {
+		s.ws()
+		if s.i >= len(s.data) || s.data[s.i] != '"' {
+			return s.err()
+		}
+
+		offset := s.i
+		key, err := s.str()
+		if err != nil {
+			return err
+		}
+
+		s.ws()
+		if s.i >= len(s.data) || s.data[s.i] != ':' {
+			return s.err()
+		}
+		s.i++
+
+		err = fn(key, offset)
+		if err != nil {
+			return err
+		}
+
+		s.ws()
+		if s.i >= len(s.data) {
+			return s.err()
+		}
+		switch s.data[s.i] {
+		case ',':
+			s.i++
+		case '}':
+			s.i++
+			return nil
+		default:
+			return s.err()
+		}
+	}
+}
+
+// array consumes an array, fn is called with the index for each item, it must consume the item.
+func (s *scanner) array(fn func(i int) error) error {
+	err := s.enter()
+	if err != nil {
+		return err
+	}
+	defer s.leave()
+
+	s.i++
+
+	s.ws()
+	if s.i < len(s.data) && s.data[s.i] == ']' {
+		s.i++
+		return nil
+	}
+
+	for i := 0; ; i++ {
+		err := fn(i)
+		if err != nil {
+			return err
+		}
+
+		s.ws()
+		if s.i >= len(s.data) {
+			return s.err()
+		}
+		switch s.data[s.i] {
+		case ',':
+			s.i++
+		case ']':
+			s.i++
+			return nil
+		default:
+			return s.err()
+		}
+	}
+}
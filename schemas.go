@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/ysmood/vary"
 )
@@ -14,9 +15,28 @@ import (
 type Schemas struct {
 	refPrefix  string
 	types      Types
+	refs       map[string]Ref
+	goTypes    map[string]reflect.Type
 	handlers   map[Ref]Hijack
 	names      map[string]map[string]int
+	renamed    map[string]string
+	typeNames  map[string]string
 	interfaces vary.Interfaces
+	nameTag    string
+
+	namePolicy      NamePolicy
+	caseInsensitive bool
+
+	mapAdditionalProperties bool
+
+	inlineSingleRefs bool
+
+	pointerPolicy     PointerPolicy
+	itemPointerPolicy PointerPolicy
+
+	formats       map[string]FormatChecker
+	keywords      map[string]KeywordChecker
+	strictFormats bool
 }
 
 type Types map[string]*Schema
@@ -29,12 +49,24 @@ func New(refPrefix string) Schemas {
 		refPrefix = "#/$defs"
 	}
 
+	formats := map[string]FormatChecker{}
+	for name, check := range Formats {
+		formats[name] = check
+	}
+
 	return Schemas{
 		refPrefix:  refPrefix,
 		types:      Types{},
+		refs:       map[string]Ref{},
+		goTypes:    map[string]reflect.Type{},
 		handlers:   map[Ref]Hijack{},
 		names:      map[string]map[string]int{},
+		renamed:    map[string]string{},
+		typeNames:  map[string]string{},
 		interfaces: vary.Default,
+		nameTag:    NameTagJSON,
+		formats:    formats,
+		keywords:   map[string]KeywordChecker{},
 	}
 }
 
@@ -44,6 +76,15 @@ func NewWithInterfaces(refPrefix string, interfaces vary.Interfaces) Schemas {
 	return s
 }
 
+// WithNameTag returns a copy of s that reads the property names from the struct tag key,
+// such as "yaml", "toml", "mapstructure", "form". The default key is "json".
+// The names, "-", omitempty and the inline options are interpreted with the semantics of the format,
+// such as the untagged names are lowercased and `yaml:",inline"` flattens the field for "yaml".
+func (s Schemas) WithNameTag(key string) Schemas {
+	s.nameTag = key
+	return s
+}
+
 // Schema is designed for typescript conversion.
 // Its fields is a strict subset of json schema fields.
 type Schema struct {
@@ -59,6 +100,7 @@ type Schema struct {
 	Enum              []JVal     `json:"enum,omitempty"`
 	Properties        Properties `json:"properties,omitempty"`
 	PatternProperties Properties `json:"patternProperties,omitempty"`
+	PropertyNames     *Schema    `json:"propertyNames,omitempty"`
 	Format            string     `json:"format,omitempty"`
 
 	// Number validation
@@ -71,15 +113,24 @@ type Schema struct {
 	Pattern string   `json:"pattern,omitempty"`
 
 	// Array validation
-	Items    *Schema `json:"items,omitempty"`
-	MinItems *int    `json:"minItems,omitempty"`
-	MaxItems *int    `json:"maxItems,omitempty"`
+	PrefixItems []*Schema `json:"prefixItems,omitempty"`
+	Items       *Schema   `json:"items,omitempty"`
+	MinItems    *int      `json:"minItems,omitempty"`
+	MaxItems    *int      `json:"maxItems,omitempty"`
 
 	// Object validation
 	Required             Required `json:"required,omitempty"`
 	AdditionalProperties *bool    `json:"additionalProperties,omitempty"`
 
+	// AdditionalPropertiesSchema is encoded as the "additionalProperties" when it's not nil,
+	// it overrides the AdditionalProperties.
+	AdditionalPropertiesSchema *Schema `json:"-"`
+
 	Defs Types `json:"$defs,omitempty"`
+
+	// Extensions are the custom keywords that start with the [KeywordPrefix], such as "x-unit",
+	// they are encoded beside the standard keywords. Use [Schemas.AddKeyword] to validate them.
+	Extensions map[string]JVal `json:"-"`
 }
 
 type Required []string
@@ -94,6 +145,9 @@ const (
 	TypeArray   SchemaType = "array"
 	TypeBool    SchemaType = "boolean"
 	TypeNull    SchemaType = "null"
+
+	// TypeUnknown is the type of the go types that json can't encode, such as the chan and func.
+	// [Schemas.Compile] returns an error for it.
 	TypeUnknown SchemaType = "unknown"
 )
 
@@ -110,11 +164,24 @@ func (s Schemas) has(r Ref) bool {
 func (s Schemas) add(r Ref, scm *Schema) {
 	if r.Unique() {
 		s.types[r.ID] = scm
+		s.refs[r.ID] = r
 	}
 }
 
 // DefineT converts the t to Schema recursively and append newly meet schemas to the schema list s.
-func (s Schemas) DefineT(t reflect.Type) *Schema { //nolint: cyclop
+// It panics if t has a map whose key type is not a string, an integer or an [encoding.TextMarshaler],
+// because json can't encode the map.
+func (s Schemas) DefineT(t reflect.Type) *Schema {
+	return s.defineT(t, s.pointerPolicy.nullable())
+}
+
+// defineItemT is like [Schemas.DefineT] but uses the item pointer policy, it's for the array items and map values.
+func (s Schemas) defineItemT(t reflect.Type) *Schema {
+	return s.defineT(t, s.itemPointerPolicy.nullable())
+}
+
+// defineT converts the t to Schema, if t is a pointer and nullable is true the schema will accept null.
+func (s Schemas) defineT(t reflect.Type, nullable bool) *Schema { //nolint: cyclop
 	r := s.RefT(t)
 	if s.has(r) {
 		return &Schema{Ref: &r}
@@ -122,6 +189,9 @@ func (s Schemas) DefineT(t reflect.Type) *Schema { //nolint: cyclop
 
 	scm := &Schema{}
 	s.add(r, scm)
+	if r.Unique() {
+		s.goTypes[r.ID] = t
+	}
 
 	if r.Package != "" {
 		scm.Title = r.Name
@@ -131,10 +201,13 @@ func (s Schemas) DefineT(t reflect.Type) *Schema { //nolint: cyclop
 	if t.Kind() == reflect.Ptr {
 		*scm = *s.DefineT(t.Elem())
 
+		if !nullable {
+			goto end
+		}
+
 		if scm.Ref == nil {
 			n := *scm
-			scm.Type = ""
-			scm.AnyOf = []*Schema{&n, {Type: TypeNull}}
+			*scm = Schema{AnyOf: []*Schema{&n, {Type: TypeNull}}}
 		} else {
 			scm.AnyOf = []*Schema{{Ref: scm.Ref}, {Type: TypeNull}}
 			scm.Ref = nil
@@ -180,12 +253,12 @@ func (s Schemas) DefineT(t reflect.Type) *Schema { //nolint: cyclop
 		scm.Type = TypeNumber
 
 	case reflect.Slice:
-		el := s.DefineT(t.Elem())
+		el := s.defineItemT(t.Elem())
 		scm.Type = TypeArray
 		scm.Items = el
 
 	case reflect.Array:
-		el := s.DefineT(t.Elem())
+		el := s.defineItemT(t.Elem())
 		l := t.Len()
 		scm.Type = TypeArray
 		scm.Items = el
@@ -194,16 +267,23 @@ func (s Schemas) DefineT(t reflect.Type) *Schema { //nolint: cyclop
 
 	case reflect.Map:
 		scm.Type = TypeObject
-		scm.PatternProperties = map[string]*Schema{
-			"": s.DefineT(t.Elem()),
-		}
+		scm.PropertyNames = s.defineMapKey(t.Key())
+		s.defineMapValue(scm, t)
 
 	case reflect.Struct:
+		if hasOption(t, OptionTuple) {
+			s.defineTuple(scm, t)
+			break
+		}
+
 		scm.Type = TypeObject
 		scm.AdditionalProperties = new(bool)
 		for i := 0; i < t.NumField(); i++ {
 			scm.mergeProps(s.DefineFieldT(t.Field(i)))
 		}
+		if scm.AdditionalPropertiesSchema != nil {
+			scm.AdditionalProperties = nil
+		}
 
 	default:
 		scm.Type = TypeUnknown
@@ -229,52 +309,191 @@ func (s Schemas) DefineFieldT(f reflect.StructField) *Schema { //nolint: cyclop
 		Properties: Properties{},
 	}
 
-	if !f.IsExported() {
+	info := s.parseField(f)
+	if info.skip {
 		return nil
 	}
 
-	tag := ParseJSONTag(f.Tag)
+	tag, format := info.tag, info.format
 
-	if tag != nil && tag.Ignore {
-		return nil
-	}
+	// expand the fields of the inline field into current struct
+	if info.inline {
+		t := indirectType(f.Type)
+		if t.Kind() == reflect.Map {
+			s.defineMapValue(scm, t)
+			return scm
+		}
 
-	// expand the fields of anonymous struct field into current struct
-	if f.Anonymous && (tag == nil || tag.Name == "") && indirectType(f.Type).Kind() == reflect.Struct {
-		for i := 0; i < f.Type.NumField(); i++ {
-			scm.mergeProps(s.DefineFieldT(f.Type.Field(i)))
+		for i := 0; i < t.NumField(); i++ {
+			scm.mergeProps(s.DefineFieldT(t.Field(i)))
 		}
 		return scm
 	}
 
 	p := s.DefineT(f.Type)
 
-	p.loadTags(false, f)
+	p.loadNestedTags("", f, f.Type)
+
+	n := s.propertyName(f, tag, format)
 
-	if p.Items != nil {
-		p.Items.loadTags(true, f)
+	if tag != nil && tag.String {
+		p.Type = TypeString
 	}
 
-	n := f.Name
+	scm.Properties[n] = p
 
-	if tag != nil {
-		if tag.Name != "" {
-			n = tag.Name
-		}
-		if tag.String {
-			p.Type = TypeString
-		}
+	if s.caseInsensitive {
+		scm.PatternProperties = Properties{caseInsensitivePattern(n): p}
 	}
 
-	scm.Properties[n] = p
+	required := !format.optional(f, tag)
 
-	if tag == nil || !tag.Omitempty {
+	if f.Type.Kind() == reflect.Ptr && s.pointerPolicy.optional() {
+		required = false
+	}
+
+	if tagOptions(f.Tag.Get(TagJSchema)).Contains(OptionRequired) {
+		required = true
+	}
+
+	if required {
 		scm.Required.Add(n)
 	}
 
 	return scm
 }
 
+// fieldInfo describes how a struct field is converted.
+type fieldInfo struct {
+	tag    *Tag
+	format nameTagFormat
+
+	// the field is not a property
+	skip bool
+
+	// the fields of the struct or the values of the map should be flattened into the parent
+	inline bool
+}
+
+func (s Schemas) parseField(f reflect.StructField) fieldInfo {
+	if !f.IsExported() {
+		return fieldInfo{skip: true}
+	}
+
+	tag := ParseTag(f.Tag, s.nameTag)
+
+	if tag != nil && tag.Ignore {
+		return fieldInfo{skip: true}
+	}
+
+	format := getNameTagFormat(s.nameTag)
+	k := indirectType(f.Type).Kind()
+
+	inline := (tag != nil && tag.Inline && (k == reflect.Struct || k == reflect.Map)) ||
+		(format.inlineEmbedded && f.Anonymous && (tag == nil || tag.Name == "") && k == reflect.Struct)
+
+	return fieldInfo{tag: tag, format: format, inline: inline}
+}
+
+// Omit is how the encoder of a name tag omits a struct field, it's returned by [Schemas.FieldOmit].
+type Omit int
+
+const (
+	// OmitNever is for the fields that are always encoded.
+	OmitNever Omit = iota
+
+	// OmitEmpty is for the fields that are omitted when they are empty, such as the json omitempty option,
+	// the empty values are the false, 0, "", nil pointer, nil interface, and any empty array, slice, or map,
+	// the zero structs are empty too for the formats other than json.
+	OmitEmpty
+
+	// OmitZero is for the fields that are omitted when they are zero or their IsZero method returns true,
+	// such as the json omitzero option.
+	OmitZero
+)
+
+// FieldOmit returns how the encoder of the name tag of s omits the struct field f,
+// it follows the same rule as the one that decides the required properties of [Schemas.DefineFieldT].
+func (s Schemas) FieldOmit(f reflect.StructField) Omit {
+	info := s.parseField(f)
+	if info.skip || info.inline || !info.format.optional(f, info.tag) {
+		return OmitNever
+	}
+
+	k := f.Type.Kind()
+	if info.tag.Omitzero && (!info.tag.Omitempty || k == reflect.Struct || k == reflect.Array) {
+		return OmitZero
+	}
+
+	return OmitEmpty
+}
+
+// FieldInline reports whether the encoder of the name tag of s flattens the struct field f into its parent,
+// such as the embedded structs of json.
+func (s Schemas) FieldInline(f reflect.StructField) bool {
+	return s.parseField(f).inline
+}
+
+// eachProperty calls fn with the property name and the field value for each property of the struct value v.
+// The fields of the inline structs are flattened, the nil inline pointers and the inline maps are skipped.
+func (s Schemas) eachProperty(v reflect.Value, fn func(name string, fv reflect.Value)) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		info := s.parseField(f)
+
+		switch {
+		case info.skip:
+		case info.inline:
+			fv := reflect.Indirect(v.Field(i))
+			if fv.IsValid() && fv.Kind() == reflect.Struct {
+				s.eachProperty(fv, fn)
+			}
+		default:
+			fn(s.propertyName(f, info.tag, info.format), v.Field(i))
+		}
+	}
+}
+
+// defineTuple defines the struct t as a fixed-length array, each field is a positional item.
+func (s Schemas) defineTuple(scm *Schema, t reflect.Type) {
+	scm.Type = TypeArray
+	scm.PrefixItems = []*Schema{}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if s.parseField(f).skip {
+			continue
+		}
+
+		p := s.DefineT(f.Type)
+		p.loadNestedTags("", f, f.Type)
+		scm.PrefixItems = append(scm.PrefixItems, p)
+	}
+
+	l := len(scm.PrefixItems)
+	scm.MinItems = &l
+	scm.MaxItems = &l
+}
+
+// WithMapAdditionalProperties returns a copy of s that uses the "additionalProperties" for the map values
+// instead of the "patternProperties" with the empty pattern.
+func (s Schemas) WithMapAdditionalProperties() Schemas {
+	s.mapAdditionalProperties = true
+	return s
+}
+
+func (s Schemas) defineMapValue(scm *Schema, t reflect.Type) {
+	v := s.defineItemT(t.Elem())
+
+	if s.mapAdditionalProperties {
+		scm.AdditionalPropertiesSchema = v
+	} else {
+		scm.PatternProperties = Properties{"": v}
+	}
+}
+
 func (s Schemas) defineInstances(scm *Schema, i *vary.Interface) {
 	is := s.PeakSchema(scm)
 	is.Type = ""
@@ -301,13 +520,13 @@ func (s Schemas) defineInstances(scm *Schema, i *vary.Interface) {
 	}
 }
 
-func jsonValTag(f reflect.StructField, tagName string) JVal { //nolint: ireturn
+func jsonValTag(f reflect.StructField, t reflect.Type, tagName string) JVal { //nolint: ireturn
 	tag, has := f.Tag.Lookup(tagName)
 	if !has {
 		return nil
 	}
 
-	d := reflect.New(f.Type).Interface()
+	d := reflect.New(t).Interface()
 
 	err := json.Unmarshal([]byte(tag), d)
 	if err == nil {
@@ -324,13 +543,13 @@ func jsonValTag(f reflect.StructField, tagName string) JVal { //nolint: ireturn
 	return nil
 }
 
-func jsonValuesTag(f reflect.StructField, tagName string) []JVal {
+func jsonValuesTag(f reflect.StructField, t reflect.Type, tagName string) []JVal {
 	tag := f.Tag.Get(tagName)
 	if tag == "" {
 		return nil
 	}
 
-	d := reflect.New(reflect.SliceOf(f.Type))
+	d := reflect.New(reflect.SliceOf(t))
 
 	err := json.Unmarshal([]byte(tag), d.Interface())
 	if err != nil {
@@ -362,37 +581,156 @@ func toInt(v string) *int {
 	return &ii
 }
 
-func (s *Schema) loadTags(item bool, f reflect.StructField) {
-	prefix := ""
-	if item {
-		prefix = JTagItemPrefix
+var tJVal = reflect.TypeOf((*JVal)(nil)).Elem()
+
+// loadTags loads the tags with the prefix into the schema, the tags that don't exist are skipped.
+// The t is the go type of the schema, it's used to parse the default and examples.
+func (s *Schema) loadTags(prefix string, f reflect.StructField, t reflect.Type) {
+	get := func(name JTag) string {
+		return f.Tag.Get(prefix + name.String())
 	}
 
-	t := f.Tag
-	s.Description = t.Get(prefix + JTagDescription.String())
-	s.Format = t.Get(prefix + JTagFormat.String())
+	if v := get(JTagDescription); v != "" {
+		s.Description = v
+	}
+	if v := get(JTagFormat); v != "" {
+		s.Format = v
+	}
+	if v := get(JTagPattern); v != "" {
+		s.Pattern = v
+	}
 
-	s.Default = jsonValTag(f, prefix+JTagDefault.String())
+	if v := jsonValTag(f, t, prefix+JTagDefault.String()); v != nil {
+		s.Default = v
+	}
+	if v := jsonValuesTag(f, t, prefix+JTagExamples.String()); v != nil {
+		s.Examples = v
+	}
 
-	s.Examples = jsonValuesTag(f, prefix+JTagExamples.String())
+	setNum := func(to **float64, name JTag) {
+		if v := toNum(get(name)); v != nil {
+			*to = v
+		}
+	}
 
-	s.Pattern = t.Get(prefix + JTagPattern.String())
-	s.MinLen = toNum(t.Get(prefix + JTagMinLen.String()))
-	s.MaxLen = toNum(t.Get(prefix + JTagMaxLen.String()))
+	setNum(&s.MinLen, JTagMinLen)
+	setNum(&s.MaxLen, JTagMaxLen)
+	setNum(&s.Min, JTagMin)
+	setNum(&s.Max, JTagMax)
 
-	s.Min = toNum(t.Get(prefix + JTagMin.String()))
-	s.Max = toNum(t.Get(prefix + JTagMax.String()))
+	for _, key := range tagKeys(f.Tag, prefix+KeywordPrefix) {
+		if s.Extensions == nil {
+			s.Extensions = map[string]JVal{}
+		}
+		s.Extensions[strings.TrimPrefix(key, prefix)] = jsonValTag(f, tJVal, key)
+	}
 
-	if s.Type == TypeArray {
-		if s.MinItems == nil {
-			s.MinItems = toInt(t.Get(prefix + JTagMinItems.String()))
+	if target := s.nonNull(); target.Type == TypeArray {
+		if target.MinItems == nil {
+			target.MinItems = toInt(get(JTagMinItems))
 		}
-		if s.MaxItems == nil {
-			s.MaxItems = toInt(t.Get(prefix + JTagMaxItems.String()))
+		if target.MaxItems == nil {
+			target.MaxItems = toInt(get(JTagMaxItems))
 		}
 	}
 }
 
+// loadNestedTags loads the tags with the prefix into the schema and
+// recursively loads the prefixed tags into the array items, map keys, and map values,
+// such as "item-item-max" for [][]int, "key-pattern" and "value-min" for map[string]int.
+func (s *Schema) loadNestedTags(prefix string, f reflect.StructField, t reflect.Type) {
+	s.loadTags(prefix, f, t)
+
+	target := s.nonNull()
+	t = indirectType(t)
+
+	if k := t.Kind(); target.Items != nil && (k == reflect.Slice || k == reflect.Array) {
+		target.Items.loadNestedTags(prefix+JTagItemPrefix, f, t.Elem())
+	}
+
+	if t.Kind() == reflect.Map {
+		target.loadMapTags(prefix, f, t)
+	}
+}
+
+func (s *Schema) loadMapTags(prefix string, f reflect.StructField, t reflect.Type) {
+	if s.AdditionalPropertiesSchema != nil {
+		s.AdditionalPropertiesSchema.loadNestedTags(prefix+JTagValuePrefix, f, t.Elem())
+	}
+
+	if s.PropertyNames == nil {
+		s.PropertyNames = &Schema{}
+	}
+	s.PropertyNames.loadTags(prefix+JTagKeyPrefix, f, t.Key())
+
+	if v, has := s.PatternProperties[""]; has {
+		v.loadNestedTags(prefix+JTagValuePrefix, f, t.Elem())
+
+		if p := s.PropertyNames.Pattern; p != "" && p != patternInteger && p != patternUnsigned {
+			delete(s.PatternProperties, "")
+			s.PatternProperties[p] = v
+			s.PropertyNames.Pattern = ""
+			s.AdditionalProperties = new(bool)
+		}
+	}
+
+	if reflect.ValueOf(*s.PropertyNames).IsZero() {
+		s.PropertyNames = nil
+	}
+}
+
+// nonNull returns the non-null schema of the nullable schema, such as the schema of a pointer,
+// otherwise returns the schema itself.
+func (s *Schema) nonNull() *Schema {
+	if len(s.AnyOf) == 2 && s.AnyOf[1].Type == TypeNull && s.AnyOf[0].Ref == nil {
+		return s.AnyOf[0]
+	}
+	return s
+}
+
+// MarshalJSON encodes the AdditionalPropertiesSchema as the "additionalProperties" if it's set,
+// and the Extensions after the standard keywords in the order of the names.
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+
+	var b []byte
+	var err error
+
+	if s.AdditionalPropertiesSchema == nil {
+		b, err = json.Marshal(plain(s))
+	} else {
+		b, err = json.Marshal(struct {
+			plain
+			AdditionalProperties *Schema `json:"additionalProperties"`
+		}{plain(s), s.AdditionalPropertiesSchema})
+	}
+	if err != nil || len(s.Extensions) == 0 {
+		return b, err
+	}
+
+	names := []string{}
+	for name := range s.Extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b = b[:len(b)-1]
+	for _, name := range names {
+		v, err := json.Marshal(s.Extensions[name])
+		if err != nil {
+			return nil, fmt.Errorf("jschema: invalid value of %q: %w", name, err)
+		}
+		if len(b) > 1 {
+			b = append(b, ',')
+		}
+		b = strconv.AppendQuote(b, name)
+		b = append(b, ':')
+		b = append(b, v...)
+	}
+
+	return append(b, '}'), nil
+}
+
 func (s *Schema) mergeProps(target *Schema) {
 	if s.Properties == nil {
 		s.Properties = Properties{}
@@ -408,4 +746,17 @@ func (s *Schema) mergeProps(target *Schema) {
 		}
 	}
 	s.Required.Add(target.Required...)
+
+	if s.AdditionalPropertiesSchema == nil {
+		s.AdditionalPropertiesSchema = target.AdditionalPropertiesSchema
+	}
+
+	for k, v := range target.PatternProperties {
+		if s.PatternProperties == nil {
+			s.PatternProperties = Properties{}
+		}
+		if _, has := s.PatternProperties[k]; !has {
+			s.PatternProperties[k] = v
+		}
+	}
 }
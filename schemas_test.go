@@ -201,6 +201,29 @@ func TestNameConflict(t *testing.T) {
 	g.Snapshot("conflict", c.JSON())
 }
 
+func TestLoad(t *testing.T) {
+	g := got.T(t)
+
+	type Time struct {
+		Name string
+	}
+
+	c := jschema.New("")
+	c.Define(time.Time{})
+	c.Define(Time{})
+
+	l := jschema.New("")
+	types := c.JSON()
+	for _, r := range c.Refs() {
+		l.Load(r, types[r.ID])
+	}
+
+	g.Eq(l.String(), c.String())
+	g.Eq(l.Define(Time{}), c.Define(Time{}))
+	g.Eq(l.Define(Time{}).Ref.ID, "Time1")
+	g.Eq(l.String(), c.String())
+}
+
 func TestRawMessage(t *testing.T) {
 	g := got.T(t)
 
@@ -520,3 +543,428 @@ func TestAnyOfInterface(t *testing.T) {
 
 	g.Snapshot("any", s.JSON())
 }
+
+func TestNameTag(t *testing.T) {
+	g := got.T(t)
+
+	type Base struct {
+		ID int `yaml:"id"`
+	}
+
+	type Embedded struct {
+		Val int
+	}
+
+	type Config struct {
+		Base `yaml:",inline"`
+		Embedded
+		Name     string            `yaml:"name,omitempty"`
+		Ignore   string            `yaml:"-"`
+		Untagged bool              `json:"untagged"`
+		Extra    map[string]string `yaml:",inline"`
+	}
+
+	s := jschema.New("").WithNameTag("yaml")
+
+	s.Define(Config{})
+
+	g.Eq(g.JSON(g.ToJSONString(s.PeakSchema(Config{}))), map[string]interface{}{
+		"additionalProperties": false,
+		"description":          "github.com/ysmood/jschema_test.Config",
+		"patternProperties": map[string]interface{}{
+			"": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"properties": map[string]interface{}{
+			"embedded": map[string]interface{}{
+				"$ref": "#/$defs/Embedded",
+			},
+			"id": map[string]interface{}{
+				"type": "integer",
+			},
+			"name": map[string]interface{}{
+				"type": "string",
+			},
+			"untagged": map[string]interface{}{
+				"type": "boolean",
+			},
+		},
+		"required": []interface{}{
+			"id",
+			"embedded",
+			"untagged",
+		},
+		"title": "Config",
+		"type":  "object",
+	})
+
+	g.Eq(jschema.ParseTag(`mapstructure:",squash"`, "mapstructure"), &jschema.Tag{Inline: true})
+	g.Eq(jschema.ParseTag(`toml:"a,omitempty"`, "toml"), &jschema.Tag{Name: "a", Omitempty: true})
+	g.Eq(jschema.ParseTag(`yaml:"a,string"`, "yaml"), &jschema.Tag{Name: "a"})
+}
+
+func TestNamePolicy(t *testing.T) {
+	g := got.T(t)
+
+	type A struct {
+		HTTPServerID int
+		Field2Name   int `json:",omitempty"`
+		Tagged       int `json:"TAGGED"`
+	}
+
+	names := func(s jschema.Schemas) []string {
+		s.Define(A{})
+		scm := s.PeakSchema(A{})
+		g.Len(scm.Properties, 3)
+		return scm.Required
+	}
+
+	g.Eq(names(jschema.New("").WithNamePolicy(jschema.NameSnakeCase)), []string{"http_server_id", "TAGGED"})
+	g.Eq(names(jschema.New("").WithNamePolicy(jschema.NameKebabCase)), []string{"http-server-id", "TAGGED"})
+	g.Eq(names(jschema.New("").WithNamePolicy(jschema.NameCamelCase)), []string{"httpServerId", "TAGGED"})
+
+	s := jschema.New("").WithNamePolicy(jschema.NameCamelCase).WithCaseInsensitiveNames()
+	s.Define(A{})
+	scm := s.PeakSchema(A{})
+	g.NotNil(scm.Properties["field2Name"])
+	g.Eq(scm.PatternProperties["^[hH][tT][tT][pP][sS][eE][rR][vV][eE][rR][iI][dD]$"], scm.Properties["httpServerId"])
+
+	js := s.JSON()
+	schema := gojsonschema.NewGoLoader(map[string]interface{}{
+		"$ref":  "#/$defs/A",
+		"$defs": js,
+	})
+	result, err := gojsonschema.Validate(schema, gojsonschema.NewGoLoader(map[string]interface{}{
+		"httpServerId": 1, "HTTPSERVERID": 2, "tagged": 3, "TAGGED": 3,
+	}))
+	g.E(err)
+	g.Desc("%v", result.Errors()).True(result.Valid())
+}
+
+func TestNestedTags(t *testing.T) {
+	g := got.T(t)
+
+	type A struct {
+		Matrix  [][]float64            `item-maxItems:"3" item-item-max:"1"`
+		Headers map[string][]string    `key-pattern:"^[A-Z]" value-minItems:"1" value-item-maxLen:"10"`
+		Ptr     *[]*int                `item-min:"2" item-default:"3"`
+		Formats []map[string]time.Time `item-value-format:"date-time"`
+	}
+
+	s := jschema.New("")
+	s.Define(A{})
+
+	g.Eq(g.JSON(g.ToJSONString(s.PeakSchema(A{}).Properties)), map[string]interface{}{
+		"Formats": map[string]interface{}{
+			"items": map[string]interface{}{
+				"patternProperties": map[string]interface{}{
+					"": map[string]interface{}{
+						"$ref":   "#/$defs/Time",
+						"format": "date-time",
+					},
+				},
+				"type": "object",
+			},
+			"type": "array",
+		},
+		"Headers": map[string]interface{}{
+			"additionalProperties": false,
+			"patternProperties": map[string]interface{}{
+				"^[A-Z]": map[string]interface{}{
+					"items": map[string]interface{}{
+						"maxLength": 10.0,
+						"type":      "string",
+					},
+					"minItems": 1.0,
+					"type":     "array",
+				},
+			},
+			"type": "object",
+		},
+		"Matrix": map[string]interface{}{
+			"items": map[string]interface{}{
+				"items": map[string]interface{}{
+					"maximum": 1.0,
+					"type":    "number",
+				},
+				"maxItems": 3.0,
+				"type":     "array",
+			},
+			"type": "array",
+		},
+		"Ptr": map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{
+					"items": map[string]interface{}{
+						"anyOf": []interface{}{
+							map[string]interface{}{
+								"type": "integer",
+							},
+							map[string]interface{}{
+								"type": "null",
+							},
+						},
+						"default": 3.0,
+						"minimum": 2.0,
+					},
+					"type": "array",
+				},
+				map[string]interface{}{
+					"type": "null",
+				},
+			},
+		},
+	})
+}
+
+type TextKey struct{ v string }
+
+func (k TextKey) MarshalText() ([]byte, error) { return []byte(k.v), nil }
+
+type StrEnum string
+
+func (StrEnum) Values() []string { return []string{"b", "a"} }
+
+func (e StrEnum) MarshalJSON() ([]byte, error) { return json.Marshal(string(e)) }
+
+func (e *StrEnum) UnmarshalJSON(b []byte) error { return json.Unmarshal(b, (*string)(e)) }
+
+type RawKey string
+
+func (RawKey) Values() []json.RawMessage {
+	return []json.RawMessage{json.RawMessage(`"a"`), json.RawMessage(` 1 `)}
+}
+
+func (e RawKey) MarshalJSON() ([]byte, error) { return json.Marshal(string(e)) }
+
+func (e *RawKey) UnmarshalJSON(b []byte) error { return json.Unmarshal(b, (*string)(e)) }
+
+func TestMapKey(t *testing.T) {
+	g := got.T(t)
+
+	type A struct {
+		Int     map[int]string
+		Uint    map[uint8]string
+		Enum    map[StrEnum]string
+		Raw     map[RawKey]string
+		Text    map[TextKey]string
+		Time    map[time.Time]string
+		Str     map[string]string `key-minLen:"1"`
+		Pattern map[string]int    `key-pattern:"^a" key-maxLen:"3"`
+	}
+
+	s := jschema.New("")
+	s.Define(A{})
+
+	props := g.JSON(g.ToJSONString(s.PeakSchema(A{}).Properties)).(map[string]interface{})
+
+	keys := func(name string) interface{} {
+		return props[name].(map[string]interface{})["propertyNames"]
+	}
+
+	g.Eq(keys("Int"), map[string]interface{}{"pattern": "^-?[0-9]+$"})
+	g.Eq(keys("Uint"), map[string]interface{}{"pattern": "^[0-9]+$"})
+	g.Eq(keys("Enum"), map[string]interface{}{"enum": []interface{}{"a", "b"}})
+	g.Eq(keys("Raw"), map[string]interface{}{"enum": []interface{}{"1", "a"}})
+	g.Nil(keys("Text"))
+	g.Eq(keys("Time"), map[string]interface{}{"format": "date-time"})
+	g.Eq(keys("Str"), map[string]interface{}{"minLength": 1.0})
+	g.Eq(props["Pattern"], map[string]interface{}{
+		"additionalProperties": false,
+		"patternProperties": map[string]interface{}{
+			"^a": map[string]interface{}{"type": "integer"},
+		},
+		"propertyNames": map[string]interface{}{"maxLength": 3.0},
+		"type":          "object",
+	})
+
+	type Bad struct {
+		M map[float64]string
+	}
+
+	g.Eq(g.Panic(func() { s.Define(Bad{}) }), "jschema: unsupported map key type float64")
+}
+
+func TestMapAdditionalProperties(t *testing.T) {
+	g := got.T(t)
+
+	type A struct {
+		M     map[string]int `value-min:"1"`
+		Extra map[string]int `yaml:",inline"`
+	}
+
+	s := jschema.New("").WithNameTag("yaml").WithMapAdditionalProperties()
+	s.Define(A{})
+
+	g.Eq(g.JSON(g.ToJSONString(s.PeakSchema(A{}))), map[string]interface{}{
+		"additionalProperties": map[string]interface{}{
+			"type": "integer",
+		},
+		"description": "github.com/ysmood/jschema_test.A",
+		"properties": map[string]interface{}{
+			"m": map[string]interface{}{
+				"additionalProperties": map[string]interface{}{
+					"minimum": 1.0,
+					"type":    "integer",
+				},
+				"type": "object",
+			},
+		},
+		"required": []interface{}{"m"},
+		"title":    "A",
+		"type":     "object",
+	})
+}
+
+type Location struct {
+	_     struct{} `jschema:"tuple"`
+	Lat   float64  `min:"-90" max:"90"`
+	Lng   float64
+	Label string `json:"-"`
+	Note  *Location
+}
+
+func TestTuple(t *testing.T) {
+	g := got.T(t)
+
+	type A struct {
+		Loc Location
+	}
+
+	s := jschema.New("")
+	scm := s.ToStandAlone(s.Define(A{}))
+
+	g.Eq(g.JSON(g.ToJSONString(scm.Defs["Location"])), map[string]interface{}{
+		"description": "github.com/ysmood/jschema_test.Location",
+		"maxItems":    3.0,
+		"minItems":    3.0,
+		"prefixItems": []interface{}{
+			map[string]interface{}{
+				"maximum": 90.0,
+				"minimum": -90.0,
+				"type":    "number",
+			},
+			map[string]interface{}{
+				"type": "number",
+			},
+			map[string]interface{}{
+				"anyOf": []interface{}{
+					map[string]interface{}{
+						"$ref": "#/$defs/Location",
+					},
+					map[string]interface{}{
+						"type": "null",
+					},
+				},
+			},
+		},
+		"title": "Location",
+		"type":  "array",
+	})
+
+	ref := s.Ref(A{})
+
+	g.E(s.ValidateJSON(ref, []byte(`{"Loc": [1, 2, null]}`)))
+	g.E(s.ValidateJSON(ref, []byte(`{"Loc": [1, 2, [3, 4, null]]}`)))
+
+	// The prefixItems are checked by position, including the recursive one.
+	g.Eq(s.ValidateJSON(ref, []byte(`{"Loc": [100, 2, null]}`)).Error(),
+		"jschema: invalid value: /Loc/0: Must be less than or equal to 90")
+	g.Eq(s.ValidateJSON(ref, []byte(`{"Loc": [1, "a", null]}`)).Error(),
+		"jschema: invalid value: /Loc/1: Invalid type. Expected: number, given: string")
+	g.Has(s.ValidateJSON(ref, []byte(`{"Loc": [1, 2, [3, 4, 5]]}`)).Error(),
+		"/Loc/2/2: Invalid type. Expected: array, given: integer")
+	g.Eq(s.ValidateJSON(ref, []byte(`{"Loc": [1, 2]}`)).Error(),
+		"jschema: invalid value: /Loc: Array must have at least 3 items")
+}
+
+func TestOmit(t *testing.T) {
+	g := got.T(t)
+
+	type S struct{}
+
+	type A struct {
+		Int      int       `json:",omitempty"`
+		Struct   S         `json:",omitempty"`
+		Arr      [2]int    `json:",omitempty"`
+		EmptyArr [0]int    `json:",omitempty"`
+		Zero     S         `json:",omitzero"`
+		Time     time.Time `json:",omitzero"`
+		Input    string    `json:",omitempty" jschema:"required"`
+		Plain    int
+	}
+
+	s := jschema.New("")
+	s.Define(A{})
+	g.Eq(s.PeakSchema(A{}).Required, jschema.Required{"Struct", "Arr", "Input", "Plain"})
+
+	omits := []jschema.Omit{}
+	at := reflect.TypeOf(A{})
+	for i := 0; i < at.NumField(); i++ {
+		omits = append(omits, s.FieldOmit(at.Field(i)))
+	}
+	g.Eq(omits, []jschema.Omit{
+		jschema.OmitEmpty, jschema.OmitNever, jschema.OmitNever, jschema.OmitEmpty,
+		jschema.OmitZero, jschema.OmitZero, jschema.OmitEmpty, jschema.OmitNever,
+	})
+
+	type B struct {
+		Struct S `yaml:",omitempty"`
+	}
+
+	s = jschema.New("").WithNameTag("yaml")
+	s.Define(B{})
+	g.Eq(s.PeakSchema(B{}).Required, jschema.Required(nil))
+	g.Eq(s.FieldOmit(reflect.TypeOf(B{}).Field(0)), jschema.OmitEmpty)
+	g.False(s.FieldInline(reflect.TypeOf(B{}).Field(0)))
+
+	g.Eq(jschema.ParseJSONTag(`json:"a,omitzero"`), &jschema.Tag{Name: "a", Omitzero: true})
+}
+
+func TestPointerPolicy(t *testing.T) {
+	g := got.T(t)
+
+	type Node struct {
+		Next     *Node   `json:"next"`
+		Val      *int    `json:"val,omitempty"`
+		Children []*Node `json:"children"`
+	}
+
+	get := func(s jschema.Schemas) interface{} {
+		s.Define(Node{})
+		return g.JSON(g.ToJSONString(s.PeakSchema(Node{})))
+	}
+
+	ref := map[string]interface{}{"$ref": "#/$defs/Node"}
+	nullRef := map[string]interface{}{"anyOf": []interface{}{ref, map[string]interface{}{"type": "null"}}}
+	nullInt := map[string]interface{}{"anyOf": []interface{}{
+		map[string]interface{}{"type": "integer"}, map[string]interface{}{"type": "null"},
+	}}
+
+	node := func(next, val, item interface{}, required ...interface{}) interface{} {
+		return map[string]interface{}{
+			"additionalProperties": false,
+			"description":          "github.com/ysmood/jschema_test.Node",
+			"properties": map[string]interface{}{
+				"children": map[string]interface{}{"items": item, "type": "array"},
+				"next":     next,
+				"val":      val,
+			},
+			"required": required,
+			"title":    "Node",
+			"type":     "object",
+		}
+	}
+
+	g.Eq(get(jschema.New("")), node(nullRef, nullInt, nullRef, "next", "children"))
+
+	g.Eq(get(jschema.New("").WithPointerPolicy(jschema.PointerOptional)),
+		node(ref, map[string]interface{}{"type": "integer"}, nullRef, "children"))
+
+	g.Eq(get(jschema.New("").WithPointerPolicy(jschema.PointerNullableOptional)),
+		node(nullRef, nullInt, nullRef, "children"))
+
+	g.Eq(get(jschema.New("").WithItemPointerPolicy(jschema.PointerOptional)),
+		node(nullRef, nullInt, ref, "next", "children"))
+}
@@ -0,0 +1,274 @@
+package jschema
+
+import (
+	"reflect"
+	"sync"
+)
+
+// SyncSchemas is a [Schemas] that is safe for concurrent use, such as defining types from http handlers.
+// The methods are serialized by a mutex, so the concurrent definitions of the same type produce one definition,
+// and each type gets the same collision-free ID no matter how many goroutines refer to it.
+// The types that share the same name are numbered by the order of their package paths and names,
+// not the order they are defined, so the IDs don't depend on the goroutines once all the types are defined.
+// The ID of a type may change when another type of the same name is defined later, so get the [Ref]
+// again rather than keeping it. The loaded and renamed types keep their IDs.
+type SyncSchemas struct {
+	lock *sync.Mutex
+	s    Schemas
+
+	// known is the number of the types that have been numbered
+	known int
+}
+
+// NewSync wraps s to make it safe for concurrent use, s should not be used directly after the wrapping.
+func NewSync(s Schemas) *SyncSchemas {
+	ss := &SyncSchemas{lock: &sync.Mutex{}, s: s}
+	ss.settle()
+	return ss
+}
+
+// settle renumbers the types of the same name if there are new types since the last call.
+func (s *SyncSchemas) settle() {
+	if len(s.s.typeNames) != s.known {
+		s.s.renumber()
+		s.known = len(s.s.typeNames)
+	}
+}
+
+func (s *SyncSchemas) unlock() {
+	s.settle()
+	s.lock.Unlock()
+}
+
+// Do calls fn with the underlying [Schemas] while holding the lock,
+// it's for the operations that are not wrapped by [SyncSchemas].
+// The fn should not keep s or the schemas of it after it returns.
+func (s *SyncSchemas) Do(fn func(s Schemas)) {
+	s.lock.Lock()
+	defer s.unlock()
+
+	fn(s.s)
+}
+
+// Define is the concurrent version of [Schemas.Define].
+func (s *SyncSchemas) Define(v interface{}) *Schema {
+	return s.DefineT(reflect.TypeOf(v))
+}
+
+// DefineT is the concurrent version of [Schemas.DefineT], the returned schema is a copy.
+func (s *SyncSchemas) DefineT(t reflect.Type) *Schema {
+	s.lock.Lock()
+	defer s.unlock()
+
+	s.s.DefineT(t)
+	s.settle()
+
+	return s.s.DefineT(t).Clone()
+}
+
+// Ref is the concurrent version of [Schemas.Ref].
+func (s *SyncSchemas) Ref(v interface{}) Ref {
+	return s.RefT(reflect.TypeOf(v))
+}
+
+// RefT is the concurrent version of [Schemas.RefT].
+func (s *SyncSchemas) RefT(t reflect.Type) Ref {
+	s.lock.Lock()
+	defer s.unlock()
+
+	s.s.RefT(t)
+	s.settle()
+
+	return s.s.RefT(t)
+}
+
+// PeakSchema is the concurrent version of [Schemas.PeakSchema], the returned schema is a copy.
+func (s *SyncSchemas) PeakSchema(v interface{}) *Schema {
+	s.lock.Lock()
+	defer s.unlock()
+
+	s.s.PeakSchema(v)
+	s.settle()
+
+	return s.s.PeakSchema(v).Clone()
+}
+
+// SetSchema is the concurrent version of [Schemas.SetSchema].
+func (s *SyncSchemas) SetSchema(target interface{}, v *Schema) {
+	s.lock.Lock()
+	defer s.unlock()
+
+	s.s.SetSchema(target, v)
+}
+
+// Describe is the concurrent version of [Schemas.Describe].
+func (s *SyncSchemas) Describe(v interface{}, desc string) {
+	s.lock.Lock()
+	defer s.unlock()
+
+	s.s.Describe(v, desc)
+}
+
+// Hijack is the concurrent version of [Schemas.Hijack].
+func (s *SyncSchemas) Hijack(v interface{}, h Hijack) {
+	s.lock.Lock()
+	defer s.unlock()
+
+	s.s.Hijack(v, h)
+}
+
+// Load is the concurrent version of [Schemas.Load].
+func (s *SyncSchemas) Load(r Ref, scm *Schema) {
+	s.lock.Lock()
+	defer s.unlock()
+
+	s.s.Load(r, scm)
+}
+
+// Remove is the concurrent version of [Schemas.Remove].
+func (s *SyncSchemas) Remove(v interface{}) {
+	s.lock.Lock()
+	defer s.unlock()
+
+	s.s.Remove(v)
+}
+
+// Rename is the concurrent version of [Schemas.Rename].
+func (s *SyncSchemas) Rename(v interface{}, id string) error {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.Rename(v, id)
+}
+
+// Prune is the concurrent version of [Schemas.Prune].
+func (s *SyncSchemas) Prune(roots ...interface{}) []Ref {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.Prune(roots...)
+}
+
+// Refs is the concurrent version of [Schemas.Refs].
+func (s *SyncSchemas) Refs() []Ref {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.Refs()
+}
+
+// JSON is the concurrent version of [Schemas.JSON], the returned schemas are copies.
+func (s *SyncSchemas) JSON() map[string]*Schema {
+	s.lock.Lock()
+	defer s.unlock()
+
+	types := map[string]*Schema{}
+	for id, scm := range s.s.JSON() {
+		types[id] = scm.Clone()
+	}
+	return types
+}
+
+// String is the concurrent version of [Schemas.String].
+func (s *SyncSchemas) String() string {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.String()
+}
+
+// ToStandAlone is the concurrent version of [Schemas.ToStandAlone].
+func (s *SyncSchemas) ToStandAlone(scm *Schema) *Schema {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.ToStandAlone(scm)
+}
+
+// Dereference is the concurrent version of [Schemas.Dereference].
+func (s *SyncSchemas) Dereference(scm *Schema) (*Schema, error) {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.Dereference(scm)
+}
+
+// Sample is the concurrent version of [Schemas.Sample].
+func (s *SyncSchemas) Sample(ref Ref, opts SampleOptions) ([]byte, error) {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.Sample(ref, opts)
+}
+
+// Compile is the concurrent version of [Schemas.Compile].
+func (s *SyncSchemas) Compile(ref Ref) (*Validator, error) {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.Compile(ref)
+}
+
+// AddFormat is the concurrent version of [Schemas.AddFormat].
+func (s *SyncSchemas) AddFormat(name string, check FormatChecker) {
+	s.lock.Lock()
+	defer s.unlock()
+
+	s.s.AddFormat(name, check)
+}
+
+// AddKeyword is the concurrent version of [Schemas.AddKeyword].
+func (s *SyncSchemas) AddKeyword(name string, check KeywordChecker) error {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.AddKeyword(name, check)
+}
+
+// Validate is the concurrent version of [Schemas.Validate].
+func (s *SyncSchemas) Validate(v interface{}) error {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.Validate(v)
+}
+
+// ValidateJSON is the concurrent version of [Schemas.ValidateJSON].
+func (s *SyncSchemas) ValidateJSON(ref Ref, data []byte) error {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.ValidateJSON(ref, data)
+}
+
+// SetDefaults is the concurrent version of [Schemas.SetDefaults].
+func (s *SyncSchemas) SetDefaults(v interface{}) error {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.SetDefaults(v)
+}
+
+// AddExample is the concurrent version of [Schemas.AddExample].
+func (s *SyncSchemas) AddExample(v interface{}) error {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.AddExample(v)
+}
+
+// ApplyDefaults is the concurrent version of [Schemas.ApplyDefaults].
+func (s *SyncSchemas) ApplyDefaults(ptr interface{}) error {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.ApplyDefaults(ptr)
+}
+
+// ApplyDefaultsJSON is the concurrent version of [Schemas.ApplyDefaultsJSON].
+func (s *SyncSchemas) ApplyDefaultsJSON(ref Ref, data []byte) ([]byte, error) {
+	s.lock.Lock()
+	defer s.unlock()
+
+	return s.s.ApplyDefaultsJSON(ref, data)
+}
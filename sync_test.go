@@ -0,0 +1,100 @@
+package jschema_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/jschema"
+)
+
+func TestSyncSchemas(t *testing.T) {
+	g := got.T(t)
+
+	type Time struct {
+		Name string `json:"name" default:"a"`
+	}
+
+	type A struct {
+		T Time `json:"t"`
+		B []A  `json:"b"`
+	}
+
+	s := jschema.NewSync(jschema.New(""))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			s.Define(A{})
+			s.Define(time.Time{})
+			_ = s.Ref(Time{})
+			_ = s.JSON()
+			_ = s.Validate(A{})
+			_ = s.ApplyDefaults(&Time{})
+		}()
+	}
+	wg.Wait()
+
+	types := s.JSON()
+	g.Len(types, 3)
+
+	ids := map[string]string{}
+	for _, r := range s.Refs() {
+		ids[r.String()] = r.ID
+	}
+	g.Eq(s.Ref(Time{}).ID, ids[s.Ref(Time{}).String()])
+	g.Eq(s.Ref(time.Time{}).ID, ids["time.Time"])
+	g.Eq(s.Define(A{}).Ref.ID, "A")
+
+	types["A"].Title = "changed"
+	g.Eq(s.PeakSchema(A{}).Title, "A")
+
+	str := s.String()
+	s.Do(func(l jschema.Schemas) {
+		g.Eq(l.String(), str)
+	})
+}
+
+func TestSyncSchemasStableIDs(t *testing.T) {
+	g := got.T(t)
+
+	type Time struct {
+		Name string `json:"name"`
+	}
+
+	type A struct {
+		T  Time      `json:"t"`
+		T2 time.Time `json:"t2"`
+	}
+
+	x := jschema.NewSync(jschema.New(""))
+	x.Do(func(s jschema.Schemas) { s.HijackTime() })
+	x.Define(time.Time{})
+	g.Eq(x.Ref(time.Time{}).ID, "Time")
+	x.Define(A{})
+
+	y := jschema.NewSync(jschema.New(""))
+	y.Do(func(s jschema.Schemas) { s.HijackTime() })
+	y.Define(A{})
+	y.Define(time.Time{})
+
+	g.Eq(x.String(), y.String())
+	g.Eq(x.Ref(Time{}).ID, "Time")
+	g.Eq(x.Ref(time.Time{}).ID, "Time1")
+	g.Eq(x.PeakSchema(A{}).Properties["t2"].Ref.ID, "Time1")
+	g.Eq(x.Define(time.Time{}).Ref.ID, "Time1")
+
+	g.E(x.Validate(A{}))
+
+	l := jschema.New("")
+	l.HijackTime()
+	l.Define(time.Time{})
+	l.Define(Time{})
+	l.Define(A{})
+	z := jschema.NewSync(l)
+	g.Eq(z.String(), x.String())
+}
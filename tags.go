@@ -1,6 +1,10 @@
 package jschema
 
-import "strings"
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
 
 type JTag string
 
@@ -18,8 +22,33 @@ const (
 	JTagMaxItems    JTag = "maxItems"
 )
 
+// TagJSchema is the struct tag key for the comma-separated jschema options, such as `jschema:"tuple"`.
+const TagJSchema = "jschema"
+
+// OptionTuple marks the struct as a tuple, each exported field of it becomes a positional item of the "prefixItems".
+// Put it on a blank field of the struct, such as:
+//
+//	type LatLng struct {
+//		_ struct{} `jschema:"tuple"`
+//		Lat float64
+//		Lng float64
+//	}
+const OptionTuple = "tuple"
+
+// OptionRequired marks the field as required even if the encoder may omit it,
+// such as `json:"name,omitempty" jschema:"required"` for a field that is required for input.
+const OptionRequired = "required"
+
+// JTagItemPrefix is the prefix of [JTag] to set the array items, such as "item-min".
+// Prefixes can be chained to reach nested collections, such as "item-item-max" for [][]float64.
 const JTagItemPrefix = "item-"
 
+// JTagValuePrefix is the prefix of [JTag] to set the map values, such as "value-pattern".
+const JTagValuePrefix = "value-"
+
+// JTagKeyPrefix is the prefix of [JTag] to set the map keys, such as "key-pattern".
+const JTagKeyPrefix = "key-"
+
 func (t JTag) String() string {
 	return string(t)
 }
@@ -51,3 +80,107 @@ func (o tagOptions) Contains(option string) bool {
 	}
 	return false
 }
+
+// tagKeys returns the keys of the struct tag that have the prefix, such as "x-unit" of `json:"a" x-unit:"ms"`.
+func tagKeys(tag reflect.StructTag, prefix string) []string {
+	keys := []string{}
+
+	s := string(tag)
+	for {
+		s = strings.TrimLeft(s, " ")
+
+		key, rest, found := strings.Cut(s, ":")
+		if !found || rest == "" || rest[0] != '"' {
+			return keys
+		}
+
+		value, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return keys
+		}
+
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+
+		s = rest[len(value):]
+	}
+}
+
+// NameTagJSON is the default struct tag key to read the property names from.
+const NameTagJSON = "json"
+
+// nameTagFormat describes how an encoder names and flattens the struct fields with its struct tag.
+type nameTagFormat struct {
+	// the option to encode a field as string, such as `json:",string"`
+	stringOption string
+
+	// the options to flatten a field into its parent, such as `yaml:",inline"`
+	inlineOptions []string
+
+	// whether the untagged field names are lowercased, such as yaml
+	lowerName bool
+
+	// whether the embedded structs without a tag name are flattened, such as json
+	inlineEmbedded bool
+
+	// whether the omitempty omits the zero structs and arrays, json never omits them
+	omitZeroStructs bool
+}
+
+var nameTagFormats = map[string]nameTagFormat{
+	NameTagJSON:    {stringOption: "string", inlineEmbedded: true},
+	"yaml":         {inlineOptions: []string{"inline"}, lowerName: true, omitZeroStructs: true},
+	"toml":         {inlineEmbedded: true, omitZeroStructs: true},
+	"mapstructure": {inlineOptions: []string{"squash", "remain"}, omitZeroStructs: true},
+	"form":         {inlineEmbedded: true, omitZeroStructs: true},
+}
+
+// getNameTagFormat returns the format of the tag key, unknown keys use the json format.
+func getNameTagFormat(key string) nameTagFormat {
+	if f, has := nameTagFormats[key]; has {
+		return f
+	}
+	return nameTagFormats[NameTagJSON]
+}
+
+// hasOption reports whether any field of the struct t has the jschema option.
+func hasOption(t reflect.Type, option string) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if tagOptions(t.Field(i).Tag.Get(TagJSchema)).Contains(option) {
+			return true
+		}
+	}
+	return false
+}
+
+// optional reports whether the field may be omitted by the encoder of the format.
+func (f nameTagFormat) optional(field reflect.StructField, tag *Tag) bool {
+	if tag == nil {
+		return false
+	}
+
+	if tag.Omitzero {
+		return true
+	}
+
+	if !tag.Omitempty {
+		return false
+	}
+
+	if f.omitZeroStructs {
+		return true
+	}
+
+	// encoding/json only omits the false, 0, nil pointer, nil interface value,
+	// and any empty array, slice, map, or string.
+	//nolint: exhaustive
+	switch field.Type.Kind() {
+	case reflect.Struct:
+		return false
+	case reflect.Array:
+		return field.Type.Len() == 0
+	}
+
+	return true
+}
@@ -2,8 +2,11 @@ package jschema
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"sort"
+	"strings"
+	"unicode"
 
 	"github.com/huandu/go-clone"
 )
@@ -66,36 +69,83 @@ func (s *Schemas) SetSchema(target interface{}, v *Schema) {
 	ss.Description = desc
 }
 
-func (s *Schema) Clone() *Schema {
-	return clone.Clone(s).(*Schema) //nolint: forcetypeassert
-}
+// Clone returns a deep copy of s, defining types in the copy won't change s.
+func (s Schemas) Clone() Schemas {
+	c := s
 
-func (s *Schema) ChangeDefs(to string) {
-	if s == nil {
-		return
+	c.types = Types{}
+	for id, scm := range s.types {
+		c.types[id] = scm.Clone()
 	}
 
-	if s.Ref != nil {
-		s.Ref.Defs = to
+	c.refs = map[string]Ref{}
+	for id, r := range s.refs {
+		c.refs[id] = r
 	}
 
-	for _, ss := range s.AnyOf {
-		ss.ChangeDefs(to)
+	c.goTypes = map[string]reflect.Type{}
+	for id, t := range s.goTypes {
+		c.goTypes[id] = t
 	}
 
-	for _, p := range s.Properties {
-		p.ChangeDefs(to)
+	c.handlers = map[Ref]Hijack{}
+	for r, h := range s.handlers {
+		c.handlers[r] = h
 	}
 
-	for _, p := range s.PatternProperties {
-		p.ChangeDefs(to)
+	c.names = map[string]map[string]int{}
+	for name, list := range s.names {
+		c.names[name] = map[string]int{}
+		for hash, i := range list {
+			c.names[name][hash] = i
+		}
 	}
 
-	s.Items.ChangeDefs(to)
+	c.renamed = map[string]string{}
+	for hash, id := range s.renamed {
+		c.renamed[hash] = id
+	}
 
-	for _, p := range s.Defs {
-		p.ChangeDefs(to)
+	c.typeNames = map[string]string{}
+	for hash, name := range s.typeNames {
+		c.typeNames[hash] = name
 	}
+
+	c.formats = map[string]FormatChecker{}
+	for name, check := range s.formats {
+		c.formats[name] = check
+	}
+
+	c.keywords = map[string]KeywordChecker{}
+	for name, check := range s.keywords {
+		c.keywords[name] = check
+	}
+
+	return c
+}
+
+func (s *Schema) Clone() *Schema {
+	return clone.Clone(s).(*Schema) //nolint: forcetypeassert
+}
+
+// merge sets the non-zero fields of from to s.
+func (s *Schema) merge(from *Schema) {
+	dst := reflect.ValueOf(s).Elem()
+	src := reflect.ValueOf(from).Elem()
+
+	for i := 0; i < src.NumField(); i++ {
+		if f := src.Field(i); !f.IsZero() {
+			dst.Field(i).Set(f)
+		}
+	}
+}
+
+func (s *Schema) ChangeDefs(to string) {
+	s.each(func(ss *Schema) {
+		if ss.Ref != nil {
+			ss.Ref.Defs = to
+		}
+	})
 }
 
 func (s *Schemas) AnyOf(list ...interface{}) *Schema {
@@ -116,14 +166,135 @@ func (s *Schemas) Const(v JVal) *Schema {
 	return ss
 }
 
+// ToStandAlone returns a copy of scm that includes the definitions it transitively references as its $defs,
+// the existing $defs of scm are kept, such as the ones generated by [Infer].
+// The refs without go types point to the existing $defs first, if one of the existing $defs has the same ID as
+// a definition of s that is also needed it gets a numeric suffix.
+// With [Schemas.WithInlineSingleRefs] the definitions that are referenced only once are inlined.
 func (s *Schemas) ToStandAlone(scm *Schema) *Schema {
 	scm = scm.Clone()
-	scm.Defs = clone.Clone(s.types).(Types) //nolint: forcetypeassert
+
+	local := scm.Defs
+	scm.Defs = nil
+
+	roots := []*Schema{scm}
+	for _, id := range sortedIDs(local) {
+		roots = append(roots, local[id])
+	}
+
+	refs := []*Schema{}
+	for _, root := range roots {
+		root.each(func(ss *Schema) {
+			if ss.Ref != nil && (local[ss.Ref.ID] == nil || ss.Ref.Unique()) {
+				refs = append(refs, ss)
+			}
+		})
+	}
+
+	defs := Types{}
+	for id := range s.reachable(refs...) {
+		defs[id] = s.types[id].Clone()
+	}
+
+	for _, id := range sortedIDs(local) {
+		to := id
+		for i := 1; defs[to] != nil || (to != id && local[to] != nil); i++ {
+			to = fmt.Sprintf("%s%d", id, i)
+		}
+
+		if to != id {
+			renameLocalRefs(roots, id, to)
+		}
+		defs[to] = local[id]
+	}
+
+	if s.inlineSingleRefs {
+		inlineSingleRefs(scm, defs)
+	}
+	if len(defs) > 0 {
+		scm.Defs = defs
+	}
+
 	scm.ChangeDefs("#/$defs")
 
 	return scm
 }
 
+// renameLocalRefs changes the refs to the local definition from to the id to in the roots,
+// the refs to the go types are kept.
+func renameLocalRefs(roots []*Schema, from, to string) {
+	for _, root := range roots {
+		root.each(func(ss *Schema) {
+			if ss.Ref != nil && ss.Ref.ID == from && !ss.Ref.Unique() {
+				ss.Ref.ID = to
+				ss.Ref.Name = to
+			}
+		})
+	}
+}
+
+func sortedIDs(types Types) []string {
+	ids := make([]string, 0, len(types))
+	for id := range types {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// WithInlineSingleRefs returns a copy of s that makes [Schemas.ToStandAlone] inline the definitions
+// that are referenced only once, the rest stay in the $defs, such as the recursive ones.
+// The fields beside the $ref, such as the description from the struct tag, override the ones of the definition.
+func (s Schemas) WithInlineSingleRefs() Schemas {
+	s.inlineSingleRefs = true
+	return s
+}
+
+// inlineSingleRefs replaces the $refs of scm and defs that point to the definitions that are referenced only once,
+// the inlined definitions are removed from defs.
+func inlineSingleRefs(scm *Schema, defs Types) {
+	count := map[string]int{}
+	countRefs := func(ss *Schema) {
+		if ss.Ref != nil {
+			count[ss.Ref.ID]++
+		}
+	}
+
+	scm.each(countRefs)
+	for _, d := range defs {
+		d.each(countRefs)
+	}
+
+	inline := func(ss *Schema) {
+		if ss.Ref == nil || count[ss.Ref.ID] != 1 {
+			return
+		}
+
+		d, has := defs[ss.Ref.ID]
+		if !has {
+			return
+		}
+
+		ss.Ref = nil
+		d = d.Clone()
+		d.merge(ss)
+		*ss = *d
+	}
+
+	scm.each(inline)
+	for id, d := range defs {
+		if count[id] != 1 {
+			d.each(inline)
+		}
+	}
+
+	for id := range defs {
+		if count[id] == 1 {
+			delete(defs, id)
+		}
+	}
+}
+
 // SchemaT returns a standalone schema for the given type.
 func (s *Schemas) SchemaT(t reflect.Type) *Schema {
 	return s.ToStandAlone(s.DefineT(t))
@@ -139,6 +310,13 @@ func ToJValList[T any](list ...T) []JVal {
 	return to
 }
 
+// Ptr returns the pointer of v, it's useful to set the optional fields of [Schema], such as:
+//
+//	scm.Max = jschema.Ptr(10.0)
+func Ptr[T any](v T) *T {
+	return &v
+}
+
 func indirectType(t reflect.Type) reflect.Type {
 	if t.Kind() == reflect.Ptr {
 		return t.Elem()
@@ -170,6 +348,35 @@ func (r *Required) Has(name string) bool {
 	return false
 }
 
+// ExportName converts the name to a go exported identifier, such as "user_name" to "UserName".
+// The name that doesn't start with a letter gets the "X" prefix, such as "1st" to "X1st".
+func ExportName(name string) string {
+	b := strings.Builder{}
+	upper := true
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		b.WriteRune(r)
+	}
+
+	s := b.String()
+
+	if s == "" || !unicode.IsLetter([]rune(s)[0]) {
+		s = "X" + s
+	}
+
+	return s
+}
+
 func toString(v any) string {
 	b, _ := json.Marshal(v) //nolint: errchkjson
 	return string(b)
@@ -1,6 +1,9 @@
 package jschema_test
 
 import (
+	"encoding/json"
+	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/ysmood/got"
@@ -60,3 +63,106 @@ func TestChangeDefs(t *testing.T) {
 
 	g.Eq(old, g.JSON(s.String()))
 }
+
+func TestUnmarshalSchema(t *testing.T) {
+	g := got.T(t)
+
+	type A struct {
+		M map[int]*A
+		B bool
+	}
+
+	s := jschema.New("").WithMapAdditionalProperties()
+	s.Define(A{})
+
+	var types jschema.Types
+	g.E(json.Unmarshal([]byte(s.String()), &types))
+
+	g.Eq(types["A"].Properties["M"].AdditionalPropertiesSchema.AnyOf[0].Ref, &jschema.Ref{
+		Defs: "#/$defs", Name: "A", ID: "A",
+	})
+	g.False(*types["A"].AdditionalProperties)
+	g.Eq(g.ToJSONString(types), g.ToJSONString(s.JSON()))
+}
+
+func TestToStandAloneReachable(t *testing.T) {
+	g := got.T(t)
+
+	type A struct {
+		ID int
+	}
+
+	type Node struct {
+		A        A       `json:"a" description:"the a" max:"1"`
+		Children []*Node `json:"children"`
+	}
+
+	type Other struct{}
+
+	s := jschema.New("")
+	s.Define(Other{})
+	s.Define(Node{})
+
+	defs := func(scm *jschema.Schema) []string {
+		list := []string{}
+		for id := range scm.Defs {
+			list = append(list, id)
+		}
+		sort.Strings(list)
+		return list
+	}
+
+	g.Eq(defs(s.SchemaT(reflect.TypeOf([]int{}))), []string{})
+	g.Eq(defs(s.ToStandAlone(s.Define([]Node{}))), []string{"A", "Node"})
+
+	inlined := s.WithInlineSingleRefs()
+
+	scm := inlined.ToStandAlone(s.Define([]Node{}))
+	g.Eq(defs(scm), []string{"Node"})
+	g.Eq(scm.Items.Ref.ID, "Node")
+	g.Eq(scm.Defs["Node"].Properties["a"].Title, "A")
+	g.Eq(scm.Defs["Node"].Properties["a"].Description, "the a")
+	g.Eq(*scm.Defs["Node"].Properties["a"].Max, 1.0)
+	g.Eq(scm.Defs["Node"].Properties["a"].Ref, (*jschema.Ref)(nil))
+	g.Eq(scm.Defs["Node"].Properties["children"].Items.AnyOf[0].Ref.ID, "Node")
+	g.Eq(s.Get("Node").Properties["a"].Ref.ID, "A")
+
+	scm = inlined.ToStandAlone(s.Define(A{}))
+	g.Nil(scm.Defs)
+	g.Eq(scm.Title, "A")
+	g.Eq(scm.Properties["ID"].Type, jschema.TypeInteger)
+}
+
+func TestToStandAloneDefsCollision(t *testing.T) {
+	g := got.T(t)
+
+	type A struct {
+		ID int
+	}
+
+	s := jschema.New("")
+
+	scm, err := jschema.Infer([]byte(`[{"a": {"b": 1}}, {"c": {"b": 3}}]`))
+	g.E(err)
+	g.Eq(scm.Items.Properties["a"].Ref.ID, "A")
+	scm.Items.Properties["d"] = s.Define(A{})
+
+	out := s.ToStandAlone(scm)
+	g.Eq(out.Items.Properties["a"].Ref.ID, "A1")
+	g.Eq(out.Items.Properties["c"].Ref.ID, "A1")
+	g.Eq(out.Items.Properties["d"].Ref.ID, "A")
+	g.Eq(out.Defs["A"].Properties["ID"].Type, jschema.TypeInteger)
+	g.Eq(out.Defs["A1"].Properties["b"].Type, jschema.TypeInteger)
+
+	// The input is not changed.
+	g.Eq(scm.Items.Properties["a"].Ref.ID, "A")
+}
+
+func TestExportName(t *testing.T) {
+	g := got.T(t)
+
+	g.Eq(jschema.ExportName("user_name"), "UserName")
+	g.Eq(jschema.ExportName("a-b c"), "ABC")
+	g.Eq(jschema.ExportName("1st"), "X1st")
+	g.Eq(jschema.ExportName("--"), "X")
+}
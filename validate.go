@@ -0,0 +1,140 @@
+package jschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ValidationError is a violation of a schema keyword.
+type ValidationError struct {
+	// Path is the json pointer of the invalid value, such as "/children/0/id", the root is "".
+	Path string
+
+	// Keyword is the violated schema keyword, such as "minimum".
+	Keyword string
+
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s", path, e.Message)
+}
+
+// NewValidationError returns the violation of the keyword by the value at the json pointer path,
+// the message is the same as the one of [Schemas.ValidateJSON]. The args are the values of the keyword:
+//
+//   - "type": the expected type and the given type
+//   - "required", "additionalProperties", "propertyNames": the property name
+//   - "minimum", "maximum", "minLength", "maxLength", "minItems", "maxItems": the limit
+//   - "pattern", "format": the pattern or the format name
+//   - "enum": the json of the allowed values joined by ", "
+//
+// It's for the validators generated by the codegen package.
+func NewValidationError(path, keyword string, args ...interface{}) *ValidationError {
+	arg := func(i int) string {
+		if i >= len(args) {
+			return ""
+		}
+		if f, ok := args[i].(float64); ok {
+			return formatNum(f)
+		}
+		return fmt.Sprint(args[i])
+	}
+
+	var msg string
+
+	switch keyword {
+	case "type":
+		msg = fmt.Sprintf("Invalid type. Expected: %s, given: %s", arg(0), arg(1))
+	case "required":
+		msg = fmt.Sprintf("%s is required", arg(0))
+	case "additionalProperties":
+		msg = fmt.Sprintf("Additional property %s is not allowed", arg(0))
+	case "propertyNames":
+		msg = fmt.Sprintf("Property name of %q does not match", arg(0))
+	case "anyOf":
+		msg = "Must validate at least one schema (anyOf)"
+	case "minimum":
+		msg = fmt.Sprintf("Must be greater than or equal to %s", arg(0))
+	case "maximum":
+		msg = fmt.Sprintf("Must be less than or equal to %s", arg(0))
+	case "minLength":
+		msg = fmt.Sprintf("String length must be greater than or equal to %s", arg(0))
+	case "maxLength":
+		msg = fmt.Sprintf("String length must be less than or equal to %s", arg(0))
+	case "minItems":
+		msg = fmt.Sprintf("Array must have at least %s items", arg(0))
+	case "maxItems":
+		msg = fmt.Sprintf("Array must have at most %s items", arg(0))
+	case "pattern":
+		msg = fmt.Sprintf("Does not match pattern '%s'", arg(0))
+	case "format":
+		msg = fmt.Sprintf("Does not match format '%s'", arg(0))
+	case "enum":
+		field := rootField
+		if path != "" {
+			field = strings.ReplaceAll(path[1:], "/", ".")
+		}
+		msg = fmt.Sprintf("%s must be one of the following: %s", field, arg(0))
+	default:
+		msg = fmt.Sprintf("Must validate the %s", keyword)
+	}
+
+	return &ValidationError{Path: path, Keyword: keyword, Message: msg}
+}
+
+// rootField is the field name of the root value in the messages.
+const rootField = "(root)"
+
+// formatNum formats the number the same as gojsonschema.
+func formatNum(f float64) string {
+	return strconv.FormatFloat(f, 'g', 10, 64)
+}
+
+// ValidationErrors is the list of the violations of a json value.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	list := []string{}
+	for _, err := range e {
+		list = append(list, err.Error())
+	}
+	return "jschema: invalid value: " + strings.Join(list, "; ")
+}
+
+// Validate validates the json encoding of v against the schema of its type, the type will be defined if it's new.
+// It returns [ValidationErrors] if v doesn't match the schema.
+func (s Schemas) Validate(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	scm := s.DefineT(reflect.TypeOf(v))
+
+	return s.validate(scm, b)
+}
+
+// ValidateJSON validates the json data against the schema of the ref.
+// It returns [ValidationErrors] if the data doesn't match the schema.
+// It compiles the schema with [Schemas.Compile] for each call, so it checks the formats of [Schemas.AddFormat]
+// and the keywords of [Schemas.AddKeyword]. Compile the schema once to validate lots of data.
+func (s Schemas) ValidateJSON(ref Ref, data []byte) error {
+	return s.validate(&Schema{Ref: &ref}, data)
+}
+
+func (s Schemas) validate(scm *Schema, data []byte) error {
+	v, err := s.compile(scm)
+	if err != nil {
+		return err
+	}
+
+	return v.Validate(data)
+}